@@ -0,0 +1,68 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
+	"github.com/spf13/cobra"
+)
+
+var applyFile string
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Bring the project to the state declared in a spec",
+	Long: `Bring the project to the state declared in gomakase.spec.yaml: generate the project
+when there is no gen.yaml yet, add the contexts and plugins that are missing and report
+the ones already there as satisfied. Applying the same spec twice changes nothing.
+
+The project lives in the directory of the spec, unless --project-dir is set. Apply never
+changes the module or the options of an existing project.`,
+	Args: cobra.NoArgs,
+	Example: `gomakase apply
+gomakase apply --file services/shop/gomakase.spec.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(applyFile)
+		if err != nil {
+			log.Fatalf("Error reading spec: %v", err)
+		}
+		spec, err := gomakase.ParseSpec(content)
+		if err != nil {
+			log.Fatalf("Error loading spec: %v", err)
+		}
+
+		root := filepath.Dir(applyFile)
+		if projectDir != "" {
+			root = projectDir
+		}
+		result, _ := gomakase.Apply(gomakase.ApplyOptions{
+			Spec:       spec,
+			Schematics: schematicsFS(),
+			Output:     gomakase.DirFS(root),
+			Hooks:      gomakase.ExecHooks(root),
+			Events:     newEvents(),
+		})
+		exit(result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// applyCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// applyCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", gomakase.SpecFileName, "Spec file")
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the imports against the boundaries of the layers",
+	Long: `Check the imports of the contexts in internal against the boundaries of their layers:
+
+  domain-is-pure             domain must not import gorm, gin or an infrastructure layer
+  application-not-delivery   application must not import a delivery layer
+  infrastructure-is-private  a context must not import the infrastructure layer of another context
+
+Every import that breaks a rule is printed with its file and line, and the command exits with a
+non-zero status. With --output json every violation is written as one JSON object per line.`,
+	Args:    cobra.NoArgs,
+	Example: `gomakase check`,
+	Run: func(cmd *cobra.Command, args []string) {
+		violations, err := gomakase.Check(gomakase.GraphOptions{
+			Output: gomakase.DirFS(projectRoot()),
+		})
+		if err != nil {
+			log.Fatalf("Error checking the dependencies: %v", err)
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			for _, violation := range violations {
+				encoder.Encode(violation)
+			}
+		} else {
+			for _, violation := range violations {
+				fmt.Printf("  ✗ %s\n", violation)
+			}
+		}
+
+		if len(violations) > 0 {
+			if output != "json" {
+				fmt.Printf("\n%d violation(s) found.\n", len(violations))
+			}
+			os.Exit(1)
+		}
+		if output != "json" {
+			fmt.Println("No violations found.")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// checkCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// checkCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -4,15 +4,7 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"log"
-	"os"
-	"path/filepath"
-
-	"github.com/IrwantoCia/gomakase/embed"
-	"github.com/IrwantoCia/gomakase/internal/ctx_context/application"
-	"github.com/IrwantoCia/gomakase/internal/shared/command"
-	"github.com/IrwantoCia/gomakase/internal/shared/config"
-	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
 	"github.com/spf13/cobra"
 )
 
@@ -23,49 +15,26 @@ var contextCmd = &cobra.Command{
 	Long: `Generate a new context. For example:
 
 gomakase context <context_name>
+
+Names may have several words, e.g. order_item or OrderItem, and may be nested
+under parent directories, e.g. billing/invoice creates internal/billing/invoice.
+The context is wired into cmd/server/router.go with default routes, and its
+schema is added to the schemas migrated in cmd/server/migrations.go.
 `,
-	Args:    cobra.ExactArgs(1),
-	Example: `gomakase context <context_name>`,
+	Args: cobra.ExactArgs(1),
+	Example: `gomakase context <context_name>
+gomakase context order_item
+gomakase context billing/invoice`,
 	Run: func(cmd *cobra.Command, args []string) {
-		contextName := args[0]
-
-		// read the root config file
-		rootConfigFile, err := os.ReadFile("gen.yaml")
-		if err != nil {
-			log.Fatalf("Error reading root config file: %v", err)
-		}
-		rootConfig, err := config.LoadSchematic[config.RootSchematic](rootConfigFile)
-		if err != nil {
-			log.Fatalf("Error loading root config: %v", err)
-		}
-
-		contextConfigFile := filepath.Join("schematics", "context", "schematic.yaml")
-		contextConfigFileContent, err := embed.SchematicsFS.ReadFile(contextConfigFile)
-		if err != nil {
-			log.Fatalf("Error reading context config file: %v", err)
-		}
-		contextConfig, err := config.LoadSchematic[config.ContextSchematic](contextConfigFileContent)
-		if err != nil {
-			log.Fatalf("Error loading context config: %v", err)
-		}
-
-		file := file.NewFile()
-		contextService := application.NewCtxService(file, rootConfig, contextConfig)
-		err = contextService.Generate(contextName)
-		if err != nil {
-			log.Fatalf("Error generating context: %v", err)
-		}
-
-		// after commands
-		command := command.NewCommand()
-		err = command.GoModTidy()
-		if err != nil {
-			log.Fatalf("Error running go mod tidy: %v", err)
-		}
-		err = command.GoFmt()
-		if err != nil {
-			log.Fatalf("Error running go fmt: %v", err)
-		}
+		root := projectRoot()
+		result, _ := gomakase.AddContext(gomakase.ContextOptions{
+			Name:       args[0],
+			Schematics: schematicsFS(),
+			Output:     gomakase.DirFS(root),
+			Hooks:      gomakase.ExecHooks(root),
+			Events:     newEvents(),
+		})
+		exit(result)
 	},
 }
 
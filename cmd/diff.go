@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how the project drifted from its templates",
+	Long: `Show how the project drifted from its templates. Every template that produced a file
+of the project is rendered again in memory and compared with the working tree, grouped
+by schematic. Each drifted file is reported as:
+
+  user modified              the file changed since gomakase wrote it
+  template changed upstream  the template renders differently now
+  both                       the file and its template changed
+
+The diffs go from the rendered template (a/) to the working tree (b/).`,
+	Args:    cobra.NoArgs,
+	Example: `gomakase diff`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := projectRoot()
+		diffs, err := gomakase.Diff(gomakase.DiffOptions{
+			Schematics: schematicsFS(),
+			Output:     gomakase.DirFS(root),
+		})
+		if err != nil {
+			log.Fatalf("Error diffing project: %v", err)
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			for _, fileDiff := range diffs {
+				encoder.Encode(fileDiff)
+			}
+			return
+		}
+
+		// group by schematic, in the order the schematics first appear
+		groups := []string{}
+		bySchematic := map[string][]gomakase.FileDiff{}
+		counts := map[gomakase.DiffStatus]int{}
+		for _, fileDiff := range diffs {
+			counts[fileDiff.Status]++
+			if fileDiff.Status == gomakase.DiffUnchanged || fileDiff.Status == gomakase.DiffUnknown {
+				continue
+			}
+			if _, ok := bySchematic[fileDiff.Schematic]; !ok {
+				groups = append(groups, fileDiff.Schematic)
+			}
+			bySchematic[fileDiff.Schematic] = append(bySchematic[fileDiff.Schematic], fileDiff)
+		}
+
+		for _, group := range groups {
+			fmt.Printf("== %s ==\n\n", group)
+			for _, fileDiff := range bySchematic[group] {
+				name := fileDiff.Path
+				if fileDiff.Context != "" {
+					name += " (context " + fileDiff.Context + ")"
+				}
+				fmt.Printf("%s: %s\n", fileDiff.Status, name)
+				fmt.Println(fileDiff.Diff)
+			}
+		}
+
+		summary := []string{}
+		for _, status := range []gomakase.DiffStatus{
+			gomakase.DiffUserModified,
+			gomakase.DiffTemplateChanged,
+			gomakase.DiffBoth,
+			gomakase.DiffMissing,
+		} {
+			if counts[status] > 0 {
+				summary = append(summary, fmt.Sprintf("%d %s", counts[status], status))
+			}
+		}
+		if len(summary) == 0 {
+			fmt.Printf("No drift in %d generated file(s).\n", counts[gomakase.DiffUnchanged])
+		} else {
+			fmt.Printf("Drift: %s.\n", strings.Join(summary, ", "))
+		}
+		if counts[gomakase.DiffUnknown] > 0 {
+			fmt.Printf("%d file(s) have no recorded template and were not compared.\n", counts[gomakase.DiffUnknown])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// diffCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// diffCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
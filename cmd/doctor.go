@@ -0,0 +1,68 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/IrwantoCia/gomakase/internal/doctor_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the project and toolchain",
+	Long: `Diagnose the project and toolchain. Checks that gen.yaml is valid and matches go.mod,
+that the project was generated by a compatible gomakase version, that the generated files
+still exist, that cmd/server/router.go can be patched by plugins, and that go, npm and air
+are installed.`,
+	Args:    cobra.NoArgs,
+	Example: `gomakase doctor`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// without a project root the gen.yaml check reports the problem
+		root, err := findProjectRoot()
+		if err != nil {
+			root = "."
+		}
+
+		file := file.NewFileAt(root)
+		doctorService := application.NewDoctorService(file, exec.LookPath)
+
+		problems := 0
+		for _, check := range doctorService.Diagnose() {
+			if check.Err == nil {
+				fmt.Printf("  ✓ %s\n", check.Name)
+				continue
+			}
+			problems++
+			fmt.Printf("  ✗ %s\n", check.Name)
+			fmt.Printf("      %v\n", check.Err)
+			fmt.Printf("      hint: %s\n", check.Hint)
+		}
+
+		if problems > 0 {
+			fmt.Printf("\n%d problem(s) found.\n", problems)
+			os.Exit(1)
+		}
+		fmt.Println("\nNo problems found.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// doctorCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// doctorCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
+	"github.com/spf13/cobra"
+)
+
+var ejectForce bool
+
+// ejectCmd represents the eject command
+var ejectCmd = &cobra.Command{
+	Use:   "eject <schematic> <template>",
+	Short: "Copy a built-in template into the project for editing",
+	Long: `Copy a built-in template into the project's .gomakase/templates/<schematic>/<template>.
+The context and add commands use the project's copy instead of the built-in template, so it
+can be changed for this project only, e.g. to add Swagger annotations to handlers.
+
+The schematic is context, project or plugins/<name>.`,
+	Args: cobra.ExactArgs(2),
+	Example: `gomakase eject context handler.go.tmpl
+gomakase eject plugins/auth login.html.tmpl`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := projectRoot()
+		output, err := gomakase.Eject(gomakase.EjectOptions{
+			Schematic:  args[0],
+			Template:   args[1],
+			Force:      ejectForce,
+			Schematics: schematicsFS(),
+			Output:     gomakase.DirFS(root),
+		})
+		if err != nil {
+			log.Fatalf("Error ejecting template: %v", err)
+		}
+		fmt.Printf("Ejected %s/%s to %s\n", args[0], args[1], output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ejectCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// ejectCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// ejectCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	ejectCmd.Flags().BoolVar(&ejectForce, "force", false, "Overwrite an existing copy")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
+	"github.com/spf13/cobra"
+)
+
+var graphFormat string
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Draw the dependencies between the contexts and their layers",
+	Long: `Draw the dependencies between the contexts and their layers. The imports of the files in
+internal/<context>/{domain,application,delivery,infrastructure} are read, and every layer that
+imports another layer of the project, of its own context or of another one, is drawn with an
+arrow to it. Each context is a subgraph. The arrows of the imports gomakase check reports are red.
+
+The diagram is Mermaid by default, or Graphviz DOT with --format dot. With --output json the
+layers and all their imports are written as JSON instead.`,
+	Args: cobra.NoArgs,
+	Example: `gomakase graph
+gomakase graph --format dot | dot -Tsvg > contexts.svg`,
+	Run: func(cmd *cobra.Command, args []string) {
+		graph, err := gomakase.Graph(gomakase.GraphOptions{
+			Output: gomakase.DirFS(projectRoot()),
+		})
+		if err != nil {
+			log.Fatalf("Error reading the dependencies: %v", err)
+		}
+
+		if output == "json" {
+			json.NewEncoder(os.Stdout).Encode(graph)
+			return
+		}
+		switch graphFormat {
+		case "mermaid":
+			fmt.Print(graph.Mermaid())
+		case "dot":
+			fmt.Print(graph.DOT())
+		default:
+			log.Fatalf("Unknown graph format %q, must be one of [mermaid dot]", graphFormat)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// graphCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", "mermaid", "diagram format, one of [mermaid dot]")
+}
@@ -6,9 +6,8 @@ package cmd
 import (
 	"fmt"
 	"log"
-	"path"
 
-	"github.com/IrwantoCia/gomakase/embed"
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
 	"github.com/spf13/cobra"
 )
 
@@ -21,19 +20,17 @@ Each plugin represents a schematic that can be added to your project.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Available plugins:")
 
-		pluginsDir := path.Join("schematics", "plugins")
-		entries, err := embed.SchematicsFS.ReadDir(pluginsDir)
+		plugins, err := gomakase.Plugins(schematicsFS())
 		if err != nil {
 			log.Fatalf("Error reading schematics directory: %v", err)
 		}
 
-		if len(entries) == 0 {
+		if len(plugins) == 0 {
 			fmt.Println("No plugins found.")
 			return
 		}
 
-		for _, entry := range entries {
-			pluginName := entry.Name()
+		for _, pluginName := range plugins {
 			fmt.Printf("  • %s\n", pluginName)
 		}
 	},
@@ -4,62 +4,108 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
 	"log"
-	"path/filepath"
+	"os"
+	"path"
 
-	"github.com/IrwantoCia/gomakase/embed"
-	"github.com/IrwantoCia/gomakase/internal/new_context/application"
-	"github.com/IrwantoCia/gomakase/internal/shared/command"
-	"github.com/IrwantoCia/gomakase/internal/shared/config"
-	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/archive"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
 	"github.com/spf13/cobra"
 )
 
+var (
+	newDatabase      string
+	newFrontend      string
+	newHTTPFramework string
+	newVariables     map[string]string
+	newArchive       string
+	newDir           string
+)
+
 // newCmd represents the new command
 var newCmd = &cobra.Command{
-	Use:     "new <project_name>",
-	Short:   "Create a new project",
-	Args:    cobra.ExactArgs(1),
-	Example: `gomakase new <project_name>`,
+	Use:   "new <project_name>",
+	Short: "Create a new project",
+	Args:  cobra.ExactArgs(1),
+	Example: `gomakase new <project_name>
+gomakase new <project_name> --database postgres --var Author="Jane Doe"
+gomakase new github.com/<user>/<project_name> --dir <directory>
+gomakase new <project_name> --archive <project_name>.tar.gz`,
 	Run: func(cmd *cobra.Command, args []string) {
-		projectName := args[0]
-
-		projectConfigFile := filepath.Join("schematics", "project", "schematic.yaml")
-		projectConfigFileContent, err := embed.SchematicsFS.ReadFile(projectConfigFile)
-		if err != nil {
-			log.Fatalf("Error reading project config file: %v", err)
+		// the argument may be a full module path, the project is named after
+		// its last element and created in a directory of that name
+		module := userConfig.Module(args[0])
+		projectName := path.Base(module)
+		dir := newDir
+		if dir == "" {
+			dir = projectName
 		}
 
-		projectSchematic, err := config.LoadSchematic[config.ProjectSchematic](projectConfigFileContent)
-		if err != nil {
-			log.Fatalf("Error loading project config: %v", err)
+		// with --archive the project is rendered in memory and no hooks run
+		var output gomakase.Filesystem = gomakase.DirFS(dir)
+		hooks := gomakase.ExecHooks(dir)
+		var memFS *gomakase.MemFS
+		if newArchive != "" {
+			if _, err := archive.Format(newArchive); err != nil {
+				log.Fatalf("Error creating archive: %v", err)
+			}
+			memFS = gomakase.NewMemFS()
+			output = memFS
+			hooks = nil
 		}
 
-		if len(projectSchematic.Actions) == 0 {
-			log.Fatalf("No actions found in project schematic")
+		// flags take precedence over the preferred options in the user config
+		options := project.Options{
+			Database:      newDatabase,
+			Frontend:      newFrontend,
+			HTTPFramework: newHTTPFramework,
+		}
+		if !cmd.Flags().Changed("database") && userConfig.Options.Database != "" {
+			options.Database = userConfig.Options.Database
+		}
+		if !cmd.Flags().Changed("frontend") && userConfig.Options.Frontend != "" {
+			options.Frontend = userConfig.Options.Frontend
+		}
+		if !cmd.Flags().Changed("http") && userConfig.Options.HTTPFramework != "" {
+			options.HTTPFramework = userConfig.Options.HTTPFramework
 		}
 
-		file := file.NewFile()
-		newService := application.NewNewService(file)
-		newService.Generate(projectName, projectSchematic)
-
-		// after commands
-		command := command.NewCommand()
-		err = command.ChangeFolder(projectName)
-		if err != nil {
-			log.Fatalf("Error changing folder: %v", err)
+		// variables given with --var take precedence over the user config
+		variables := make(map[string]string)
+		for name, value := range userConfig.Variables {
+			variables[name] = value
+		}
+		if userConfig.Author != "" {
+			variables["Author"] = userConfig.Author
 		}
-		err = command.GoModTidy()
-		if err != nil {
-			log.Fatalf("Error running go mod tidy: %v", err)
+		if userConfig.License != "" {
+			variables["License"] = userConfig.License
 		}
-		err = command.NPMInstall()
-		if err != nil {
-			log.Fatalf("Error running npm install: %v", err)
+		for name, value := range newVariables {
+			variables[name] = value
 		}
-		err = command.GoFmt()
-		if err != nil {
-			log.Fatalf("Error running go fmt: %v", err)
+
+		result, _ := gomakase.NewProject(gomakase.ProjectOptions{
+			Module:        module,
+			Name:          projectName,
+			Database:      options.Database,
+			Frontend:      options.Frontend,
+			HTTPFramework: options.HTTPFramework,
+			Variables:     variables,
+			Schematics:    schematicsFS(),
+			Output:        output,
+			Hooks:         hooks,
+			Events:        newEvents(),
+		})
+		exit(result)
+
+		if memFS != nil {
+			err := writeArchive(newArchive, memFS, dir)
+			if err != nil {
+				log.Fatalf("Error creating archive: %v", err)
+			}
 		}
 	},
 }
@@ -76,4 +122,24 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// newCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	defaults := project.DefaultOptions()
+	newCmd.Flags().StringVar(&newDatabase, "database", defaults.Database, fmt.Sprintf("Database driver, one of %v", project.Databases))
+	newCmd.Flags().StringVar(&newFrontend, "frontend", defaults.Frontend, fmt.Sprintf("Frontend stack, one of %v", project.Frontends))
+	newCmd.Flags().StringVar(&newHTTPFramework, "http", defaults.HTTPFramework, fmt.Sprintf("HTTP framework, one of %v", project.HTTPFrameworks))
+	newCmd.Flags().StringToStringVar(&newVariables, "var", nil, "Custom template variable recorded in gen.yaml, as name=value")
+	newCmd.Flags().StringVar(&newDir, "dir", "", "Directory to create the project in (default the project name)")
+	newCmd.Flags().StringVar(&newArchive, "archive", "", "Write the project to a .tar.gz, .tgz or .zip archive instead of a directory, without running hooks")
+}
+
+func writeArchive(name string, memFS *gomakase.MemFS, dir string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = gomakase.WriteArchive(f, name, memFS, dir)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
+	"github.com/spf13/cobra"
+)
+
+// protectCmd represents the protect command
+var protectCmd = &cobra.Command{
+	Use:   "protect <path-prefix>",
+	Short: "Move routes under a path prefix behind authentication",
+	Long: `Move the routes under a path prefix into a route group that requires the
+auth plugin's middleware, creating the group when there is none. For example,
+router.GET("/admin/users", ...) becomes adminGroup.GET("/users", ...) in
+
+adminGroup := router.Group("/admin", middleware.AuthMiddleware(&authService))
+
+The auth plugin must be installed.`,
+	Args:    cobra.ExactArgs(1),
+	Example: `gomakase route protect /admin`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := projectRoot()
+		result, _ := gomakase.ProtectRoutes(gomakase.ProtectOptions{
+			Prefix: args[0],
+			Output: gomakase.DirFS(root),
+			Events: newEvents(),
+		})
+		exit(result)
+	},
+}
+
+func init() {
+	routeCmd.AddCommand(protectCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// protectCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// protectCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
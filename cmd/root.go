@@ -4,11 +4,26 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"io/fs"
+	"log"
 	"os"
 
+	"github.com/IrwantoCia/gomakase/embed"
+	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
 	"github.com/spf13/cobra"
 )
 
+var (
+	cfgFile    string
+	projectDir string
+	output     string
+	userConfig config.UserConfig
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "gomakase",
@@ -30,13 +45,79 @@ func Execute() {
 }
 
 func init() {
+	cobra.OnInitialize(initConfig)
+
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gomakase.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/gomakase/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&projectDir, "project-dir", "", "project root directory (default is the closest parent directory with a gen.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "text", "output format, one of [text json]")
+}
+
+// initConfig reads the user config file, from --config when it is set.
+func initConfig() {
+	path := cfgFile
+	if path == "" {
+		defaultPath, err := config.UserConfigPath()
+		if err != nil {
+			return
+		}
+		path = defaultPath
+	} else if _, err := os.Stat(path); err != nil {
+		log.Fatalf("Error reading config file: %v", err)
+	}
+
+	var err error
+	userConfig, err = config.LoadUserConfig(path)
+	if err != nil {
+		log.Fatalf("Error loading config file: %v", err)
+	}
+}
+
+// schematicsFS returns the built-in schematics overlaid with the schematic
+// directories from the user config.
+func schematicsFS() fs.FS {
+	return schematic.NewFS(embed.SchematicsFS, userConfig.SchematicDirs...)
+}
+
+// projectRoot returns the root directory of the project the command works on.
+// It is the --project-dir flag when set, otherwise the closest directory with
+// a gen.yaml, starting at the working directory and walking up its parents.
+func projectRoot() string {
+	root, err := findProjectRoot()
+	if err != nil {
+		log.Fatalf("Error finding project root: %v. Run the command inside a gomakase project or pass --project-dir.", err)
+	}
+	return root
+}
+
+func findProjectRoot() (string, error) {
+	if projectDir != "" {
+		return projectDir, nil
+	}
+	return project.FindRoot(".")
+}
+
+// newEvents returns the emitter a command reports its progress to. With
+// --output json every event is written to stdout as one JSON object per
+// line, otherwise as human-readable lines on stderr.
+func newEvents() gomakase.Emitter {
+	switch output {
+	case "text":
+		return event.NewTextEmitter(os.Stderr)
+	case "json":
+		return event.NewJSONEmitter(os.Stdout)
+	}
+	log.Fatalf("Unknown output format %q, must be one of [text json]", output)
+	return nil
+}
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+// exit exits with status 1 when the generator did not succeed. Its errors
+// have already been reported as events.
+func exit(result gomakase.Result) {
+	if !result.Summary.Success {
+		os.Exit(1)
+	}
 }
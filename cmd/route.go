@@ -0,0 +1,32 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// routeCmd represents the route command
+var routeCmd = &cobra.Command{
+	Use:   "route",
+	Short: "Change the routes of the project",
+	Long: `Change the routes registered in cmd/server/router.go. For example:
+
+gomakase route protect /admin
+`,
+}
+
+func init() {
+	rootCmd.AddCommand(routeCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// routeCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// routeCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
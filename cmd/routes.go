@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/IrwantoCia/gomakase/pkg/gomakase"
+	"github.com/spf13/cobra"
+)
+
+var routesJSON bool
+
+// routesCmd represents the routes command
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "List the HTTP routes of the project",
+	Long: `List the HTTP routes registered in the Routes function of cmd/server/router.go, and in the
+functions it passes the router or a group to, such as a RegisterRoutes function of a delivery
+package. Each route is shown with its method, full path, handler, the middlewares that run
+before the handler and the group it is registered on.
+
+With --json, or --output json, every route is written as one JSON object per line.`,
+	Args: cobra.NoArgs,
+	Example: `gomakase routes
+gomakase routes --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		routes, err := gomakase.Routes(gomakase.RoutesOptions{
+			Output: gomakase.DirFS(projectRoot()),
+		})
+		if err != nil {
+			log.Fatalf("Error listing routes: %v", err)
+		}
+
+		if routesJSON || output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			for _, route := range routes {
+				if err := encoder.Encode(route); err != nil {
+					log.Fatalf("Error writing routes: %v", err)
+				}
+			}
+			return
+		}
+
+		if len(routes) == 0 {
+			fmt.Println("No routes found.")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARES\tGROUP")
+		for _, route := range routes {
+			middlewares := strings.Join(route.Middlewares, ", ")
+			if middlewares == "" {
+				middlewares = "-"
+			}
+			group := route.Group
+			if group == "" {
+				group = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler, middlewares, group)
+		}
+		if err := w.Flush(); err != nil {
+			log.Fatalf("Error writing routes: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(routesCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// routesCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	routesCmd.Flags().BoolVar(&routesJSON, "json", false, "write the routes as JSON, one object per line")
+}
@@ -5,7 +5,10 @@ go 1.24.5
 require (
 	github.com/spf13/cobra v1.10.1
 	github.com/spf13/viper v1.21.0
+	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/mod v0.27.0
 	golang.org/x/text v0.28.0
+	golang.org/x/tools v0.36.0
 	gopkg.in/go-playground/assert.v1 v1.2.1
 )
 
@@ -20,6 +23,6 @@ require (
 	github.com/spf13/cast v1.10.0 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
-	go.yaml.in/yaml/v3 v3.0.4 // indirect
-	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/sync v0.16.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
 )
@@ -1,13 +1,20 @@
 package application
 
 import (
-	"embed"
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
 	"path"
+	"slices"
 
 	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/edit"
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
 	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/format"
 	"github.com/IrwantoCia/gomakase/internal/shared/parser"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
 )
 
 type AddService interface {
@@ -15,199 +22,235 @@ type AddService interface {
 }
 
 type addService struct {
-	EmbedFS      embed.FS
-	RootConfig   config.RootSchematic
+	SchematicsFS fs.FS
+	Project      *project.Project
 	PluginConfig config.PluginSchematic
+	Variables    map[string]string
 	File         file.File
+	Events       event.Emitter
 }
 
+// NewAddService returns an AddService for the plugin in pluginConfig.
+// variables override the values of the plugin's variables and are recorded
+// in the project descriptor.
 func NewAddService(
-	rootConfig config.RootSchematic,
+	project *project.Project,
 	pluginConfig config.PluginSchematic,
-	embedFS embed.FS,
+	variables map[string]string,
+	schematicsFS fs.FS,
 	file file.File,
+	events event.Emitter,
 ) AddService {
 	return &addService{
-		EmbedFS:      embedFS,
-		RootConfig:   rootConfig,
+		SchematicsFS: schematicsFS,
+		Project:      project,
 		PluginConfig: pluginConfig,
+		Variables:    variables,
 		File:         file,
+		Events:       events,
 	}
 }
 
-type ImportAction struct {
-	OutputPath string
-	ImportPath string
-	Alias      string
-}
-
 type CreateFileAction struct {
 	OutputPath string
+	Template   string
 	Content    []byte
 }
 
-type DependencyAction struct {
-	OutputPath string
-	Dependency string
-}
-
-type RouteAction struct {
-	OutputPath string
-	Route      string
-}
-
 type Job struct {
 	Type             string
 	CreateFileAction *CreateFileAction
-	ImportAction     *ImportAction
-	DependencyAction *DependencyAction
-	RouteAction      *RouteAction
+	EditAction       *edit.Action
 }
 
 func (s *addService) Generate(contextName string) error {
-	log.Printf("Adding %s", contextName)
-
-	if s.File.IsPathExists(contextName) {
-		log.Printf("Plugin is already exists, skipping...\n")
+	if s.Project.HasPlugin(contextName) {
+		s.Events.Emit(event.Event{
+			Type:   event.FileSkipped,
+			Path:   project.FileName,
+			Reason: fmt.Sprintf("plugin %s is already installed", contextName),
+		})
 		return nil
 	}
 
 	variables := s.PluginConfig.Variables
-	module := s.RootConfig.Module
+	projectData := s.Project.TemplateData()
+
+	for name := range s.Variables {
+		if !slices.ContainsFunc(variables, func(variable config.Variable) bool { return variable.Name == name }) {
+			s.Events.Emit(event.Event{
+				Type:  event.Error,
+				Error: fmt.Sprintf("plugin %s has no variable %s", contextName, name),
+			})
+			return errors.New("job error")
+		}
+	}
 
 	// populate data for templates from the variables
 	templateData := make(map[string]string)
 	for _, variable := range variables {
-		switch variable.Name {
-		case "Module":
-			templateData[variable.Name] = module
-		default:
-			templateData[variable.Name] = ""
+		templateData[variable.Name] = projectData[variable.Name]
+		if value, ok := s.Variables[variable.Name]; ok {
+			templateData[variable.Name] = value
+		}
+		if templateData[variable.Name] == "" {
+			templateData[variable.Name] = variable.Default
 		}
 	}
 
 	jobs := []Job{}
 	jobError := false
 	for _, action := range s.PluginConfig.Actions {
-		content, _ := s.EmbedFS.ReadFile(
-			path.Join(
-				"schematics",
-				"plugins",
-				contextName,
-				"templates",
-				action.Template,
-			),
+		content, _ := schematic.ReadTemplate(
+			s.File,
+			s.SchematicsFS,
+			path.Join("plugins", contextName),
+			action.Template,
 		)
 
 		outputPath, _ := s.File.ParseFilePath(action.Output, templateData)
 		importPath, _ := s.File.ParseFilePath(action.Import, templateData)
 
-		createFileAction := &CreateFileAction{
-			OutputPath: outputPath,
-			Content:    content,
+		if action.Type == "create_file" {
+			parsedContent, err := s.File.ParseTemplate(content, templateData)
+			if err != nil {
+				s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
+				jobError = true
+				continue
+			}
+			if format.IsGoFile(outputPath) {
+				parsedContent, err = format.Source(action.Template, parsedContent)
+				if err != nil {
+					s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
+					jobError = true
+					continue
+				}
+			}
+			jobs = append(jobs, Job{
+				Type: action.Type,
+				CreateFileAction: &CreateFileAction{
+					OutputPath: outputPath,
+					Template:   action.Template,
+					Content:    parsedContent,
+				},
+			})
+			continue
 		}
-		importAction := &ImportAction{
-			OutputPath: outputPath,
-			ImportPath: importPath,
+
+		envPath, _ := s.File.ParseFilePath(action.Env, templateData)
+		editAction := &edit.Action{
+			Type:       action.Type,
+			Output:     outputPath,
+			Import:     importPath,
 			Alias:      action.Alias,
-		}
-		dependencyAction := &DependencyAction{
-			OutputPath: outputPath,
+			Variable:   action.Variable,
 			Dependency: action.Dependency,
-		}
-		routeAction := &RouteAction{
-			OutputPath: outputPath,
 			Route:      action.Route,
+			Statement:  action.Statement,
+			Target:     actionTarget(action),
+			Schema:     action.Schema,
+			Group: parser.RouteGroup{
+				Name:        action.Group,
+				Prefix:      action.Prefix,
+				Middlewares: action.Middlewares,
+			},
+			Routes:  action.Routes,
+			Section: parser.ConfigSection{Name: action.Section},
+			Env:     envPath,
 		}
-		jobs = append(jobs, Job{
-			Type:             action.Type,
-			CreateFileAction: createFileAction,
-			ImportAction:     importAction,
-			DependencyAction: dependencyAction,
-			RouteAction:      routeAction,
-		})
+		for _, setting := range action.Settings {
+			editAction.Section.Settings = append(editAction.Section.Settings, parser.ConfigSetting(setting))
+		}
+		jobs = append(jobs, Job{Type: action.Type, EditAction: editAction})
 	}
 
 	if jobError {
-		log.Printf("Aborting due to errors...\n")
-		return nil
+		return errors.New("job error")
 	}
 
-Loop:
+	batch := edit.NewBatch(s.File, s.Events, templateData)
 	for _, job := range jobs {
-		switch job.Type {
-		case "create_file":
-			created := s.actionCreateFile(job.CreateFileAction, templateData)
-			if !created {
-				jobError = true
-				break Loop
-			}
-		case "add_import":
-			created := s.actionAddImport(job.ImportAction)
-			if !created {
+		if job.Type != "create_file" {
+			if err := batch.Run(*job.EditAction); err != nil {
 				jobError = true
-				break Loop
+				break
 			}
-		case "add_dependency":
-			created := s.actionAddDependency(job.DependencyAction)
-			if !created {
-				jobError = true
-				break Loop
-			}
-		case "add_route":
-			created := s.actionAddRoute(job.RouteAction)
-			if !created {
-				jobError = true
-				break Loop
-			}
-		default:
-			log.Printf("Unknown action type: %s\n", job.Type)
+			continue
+		}
+		created := s.actionCreateFile(job.CreateFileAction)
+		if !created {
 			jobError = true
+			break
 		}
+		s.Project.AddFiles(project.GeneratedFile{
+			Path:             job.CreateFileAction.OutputPath,
+			Schematic:        path.Join("plugins", contextName),
+			Template:         job.CreateFileAction.Template,
+			TemplateChecksum: project.Checksum(job.CreateFileAction.Content),
+			Checksum:         project.Checksum(job.CreateFileAction.Content),
+		})
 	}
 
-	if jobError {
-		log.Printf("Completed with errors...\n")
-		return nil
+	// a failed action leaves the files it would have edited as they were
+	if !jobError && batch.Write(s.Project) != nil {
+		jobError = true
+	}
+	if !jobError {
+		s.Project.AddPlugin(contextName, s.PluginConfig.Version, s.Variables)
+	}
+	err := project.Save(s.File, s.Project)
+	if err != nil {
+		s.Events.Emit(event.Event{Type: event.Error, Path: project.FileName, Error: err.Error()})
+		jobError = true
 	}
 
-	log.Printf("All done!\n")
+	if jobError {
+		return errors.New("job error")
+	}
 
 	return nil
 }
 
-func (s *addService) actionCreateFile(createFileAction *CreateFileAction, templateData map[string]string) bool {
-	parsedContent, err := s.File.ParseTemplate(createFileAction.Content, templateData)
+func (s *addService) actionCreateFile(createFileAction *CreateFileAction) bool {
+	err := s.File.CreateFile(createFileAction.OutputPath, createFileAction.Content)
 	if err != nil {
-		log.Printf("Error parsing template: %v\n", err)
+		s.Events.Emit(event.Event{Type: event.Error, Path: createFileAction.OutputPath, Error: err.Error()})
 		return false
 	}
-	err = s.File.CreateFile(createFileAction.OutputPath, parsedContent)
-	if err != nil {
-		log.Printf("Failed to write file: %v\n", err)
-		return false
-	}
-	log.Printf("Created: %s\n", createFileAction.OutputPath)
-	return true
-}
-
-func (s *addService) actionAddImport(importAction *ImportAction) bool {
-	parser := parser.NewASTParser(importAction.OutputPath)
-	parser.AddImport(importAction.ImportPath, importAction.Alias)
-	parser.WriteFile()
+	s.Events.Emit(event.Event{
+		Type:     event.FileCreated,
+		Path:     createFileAction.OutputPath,
+		Template: createFileAction.Template,
+	})
 	return true
 }
 
-func (s *addService) actionAddDependency(dependencyAction *DependencyAction) bool {
-	parser := parser.NewASTParser(dependencyAction.OutputPath)
-	parser.AddDependencies([]string{dependencyAction.Dependency})
-	parser.WriteFile()
-	return true
+// defaultTargets are where the AST actions insert their code in Routes
+// when they do not say.
+var defaultTargets = map[string]parser.Target{
+	"add_dependency":  parser.DependencyTarget,
+	"add_route":       parser.RouteTarget,
+	"add_route_group": parser.RouteTarget,
+	"add_statement":   {Function: parser.RoutesFunc, Receiver: "router", Anchor: parser.AnchorEnd},
 }
 
-func (s *addService) actionAddRoute(routeAction *RouteAction) bool {
-	parser := parser.NewASTParser(routeAction.OutputPath)
-	parser.AddRoute(routeAction.Route)
-	parser.WriteFile()
-	return true
+// actionTarget returns the target of an AST action: the fields the action
+// sets, and defaults for the others.
+func actionTarget(action config.PluginAction) parser.Target {
+	target := defaultTargets[action.Type]
+	if action.Function != "" {
+		// the default anchors are only meaningful in Routes
+		target.Function = action.Function
+		target.Anchor = parser.AnchorEnd
+	}
+	if action.Receiver != "" {
+		target.Receiver = action.Receiver
+	}
+	if action.Anchor != "" {
+		target.Anchor = action.Anchor
+	}
+	if action.Match != "" {
+		target.Match = action.Match
+	}
+	return target
 }
@@ -1,15 +1,20 @@
 package application
 
 import (
-	"embed"
 	"errors"
-	"log"
+	"fmt"
+	"io/fs"
 	"path"
-	"strings"
 
-	SEmbed "github.com/IrwantoCia/gomakase/embed"
 	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/edit"
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
 	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/format"
+	"github.com/IrwantoCia/gomakase/internal/shared/naming"
+	"github.com/IrwantoCia/gomakase/internal/shared/parser"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
 )
 
 type CtxService interface {
@@ -17,91 +22,203 @@ type CtxService interface {
 }
 
 type ctxService struct {
-	RootConfig    config.RootSchematic
+	Project       *project.Project
 	ContextConfig config.ContextSchematic
-	EmbedFS       embed.FS
+	SchematicsFS  fs.FS
 	File          file.File
+	Events        event.Emitter
 }
 
 func NewCtxService(
 	file file.File,
-	rootConfig config.RootSchematic,
+	project *project.Project,
 	contextConfig config.ContextSchematic,
+	schematicsFS fs.FS,
+	events event.Emitter,
 ) CtxService {
 	return &ctxService{
-		EmbedFS:       SEmbed.SchematicsFS,
+		SchematicsFS:  schematicsFS,
 		File:          file,
-		RootConfig:    rootConfig,
+		Project:       project,
 		ContextConfig: contextConfig,
+		Events:        events,
 	}
 }
 
 type Job struct {
+	Type       string
 	OutputPath string
+	Template   string
 	Content    []byte
+	// EditAction is the action of a job that edits an existing file.
+	EditAction *edit.Action
 }
 
 func (s *ctxService) Generate(
 	contextName string,
 ) error {
-	log.Printf("Generating a new context: %s\n", contextName)
+	context, err := naming.ParseContext(contextName)
+	if err != nil {
+		s.Events.Emit(event.Event{Type: event.Error, Error: err.Error()})
+		return err
+	}
 
-	if s.File.IsPathExists(path.Join("internal", strings.ToLower(contextName))) {
-		log.Printf("Context already exists, skipping...\n")
+	contextDir := path.Join("internal", context.Path)
+	if s.Project.HasContext(context.Name) || s.File.IsPathExists(contextDir) {
+		s.Events.Emit(event.Event{
+			Type:   event.FileSkipped,
+			Path:   contextDir,
+			Reason: "context already exists",
+		})
 		return nil
 	}
 
 	variables := s.ContextConfig.Variables
+	projectData := s.Project.TemplateData()
+	contextData := context.Data()
 	// populate data for templates from the variables
 	data := make(map[string]string)
 	for _, variable := range variables {
-		switch variable.Name {
-		case "Module":
-			data[variable.Name] = s.RootConfig.Module
-		case "ContextName":
-			data[variable.Name] = contextName
-		default:
-			data[variable.Name] = ""
+		if value, ok := contextData[variable.Name]; ok {
+			data[variable.Name] = value
+		} else {
+			data[variable.Name] = projectData[variable.Name]
+		}
+		if data[variable.Name] == "" {
+			data[variable.Name] = variable.Default
 		}
 	}
 
 	jobs := []Job{}
 	jobError := false
 	for _, action := range s.ContextConfig.Actions {
-		content, _ := s.EmbedFS.ReadFile(
-			path.Join(
-				"schematics",
-				"context",
-				"templates",
-				action.Template,
-			),
-		)
-
-		outputPath, _ := s.File.ParseFilePath(action.Output, data)
+		if action.Type != "create_file" {
+			editAction, err := s.editAction(action, data)
+			if err != nil {
+				s.Events.Emit(event.Event{Type: event.Error, Error: err.Error()})
+				jobError = true
+				continue
+			}
+			jobs = append(jobs, Job{Type: action.Type, OutputPath: editAction.Output, EditAction: editAction})
+			continue
+		}
+
+		content, err := schematic.ReadTemplate(s.File, s.SchematicsFS, "context", action.Template)
+		if err != nil {
+			s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
+			jobError = true
+			continue
+		}
+
+		outputPath, err := s.File.ParseFilePath(action.Output, data)
+		if err != nil {
+			s.Events.Emit(event.Event{
+				Type:     event.Error,
+				Template: action.Template,
+				Error:    fmt.Sprintf("parsing output path: %v", err),
+			})
+			jobError = true
+			continue
+		}
+
+		parsedContent, err := s.File.ParseTemplate(content, data)
+		if err != nil {
+			s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
+			jobError = true
+			continue
+		}
+
+		if format.IsGoFile(outputPath) {
+			parsedContent, err = format.Source(action.Template, parsedContent)
+			if err != nil {
+				s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
+				jobError = true
+				continue
+			}
+		}
+
 		jobs = append(jobs, Job{
+			Type:       action.Type,
 			OutputPath: outputPath,
-			Content:    content,
+			Template:   action.Template,
+			Content:    parsedContent,
 		})
 	}
 
 	if jobError {
-		log.Printf("Job error, skipping...\n")
 		return errors.New("job error")
 	}
 
+	files := []project.GeneratedFile{}
 	for _, job := range jobs {
-		log.Printf("Creating file: %s\n", job.OutputPath)
-		parsedContent, err := s.File.ParseTemplate(job.Content, data)
-		if err != nil {
-			log.Printf("Error parsing template: %v\n", err)
+		if job.Type != "create_file" {
+			// the context is wired once its files exist
 			continue
 		}
-		err = s.File.CreateFile(job.OutputPath, parsedContent)
+		err := s.File.CreateFile(job.OutputPath, job.Content)
 		if err != nil {
-			log.Printf("Error creating file %s: %v\n\n", job.OutputPath, err)
-			continue
+			s.Events.Emit(event.Event{Type: event.Error, Path: job.OutputPath, Error: err.Error()})
+			return err
+		}
+		s.Events.Emit(event.Event{Type: event.FileCreated, Path: job.OutputPath, Template: job.Template})
+		files = append(files, project.GeneratedFile{
+			Path:             job.OutputPath,
+			Schematic:        "context",
+			Context:          context.Name,
+			Template:         job.Template,
+			TemplateChecksum: project.Checksum(job.Content),
+			Checksum:         project.Checksum(job.Content),
+		})
+	}
+
+	batch := edit.NewBatch(s.File, s.Events, data)
+	for _, job := range jobs {
+		if job.Type != "create_file" && batch.Run(*job.EditAction) != nil {
+			return errors.New("job error")
 		}
 	}
+	// a failed action leaves the files it would have edited as they were
+	if batch.Write(s.Project) != nil {
+		return errors.New("job error")
+	}
 
-	return nil
+	// the context is recorded once its files exist and it is wired
+	s.Project.AddContext(context.Name)
+	s.Project.AddFiles(files...)
+	return project.Save(s.File, s.Project)
+}
+
+// editAction returns the action of a job that edits an existing file, with
+// its paths rendered.
+func (s *ctxService) editAction(action config.ContextAction, data map[string]string) (*edit.Action, error) {
+	editAction := &edit.Action{
+		Type:       action.Type,
+		Variable:   action.Variable,
+		Dependency: action.Dependency,
+		Route:      action.Route,
+		Schema:     action.Schema,
+	}
+	switch action.Type {
+	case "add_dependency":
+		editAction.Target = parser.DependencyTarget
+	case "add_route":
+		editAction.Target = parser.RouteTarget
+	}
+
+	fields := []struct {
+		value  string
+		target *string
+	}{
+		{action.Output, &editAction.Output},
+		{action.Import, &editAction.Import},
+		{action.Alias, &editAction.Alias},
+	}
+	for _, field := range fields {
+		value, err := s.File.ParseFilePath(field.value, data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", action.Type, err)
+		}
+		*field.target = value
+	}
+	return editAction, nil
 }
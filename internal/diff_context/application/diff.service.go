@@ -0,0 +1,193 @@
+package application
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/diff"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/format"
+	"github.com/IrwantoCia/gomakase/internal/shared/naming"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
+)
+
+type Status string
+
+const (
+	// Unchanged files match their template.
+	Unchanged Status = "unchanged"
+	// UserModified files were changed after gomakase last wrote them.
+	UserModified Status = "user modified"
+	// TemplateChanged files render differently with the current templates.
+	TemplateChanged Status = "template changed upstream"
+	// Both files were changed by the user and their template changed too.
+	Both Status = "both"
+	// Missing files were deleted from the working tree.
+	Missing Status = "missing"
+	// Unknown files have no recorded template, they were generated before
+	// gen.yaml recorded where files came from.
+	Unknown Status = "unknown"
+)
+
+// FileDiff compares a generated file in the working tree with its template
+// rendered again. Diff is the unified diff from the rendered template to
+// the working tree, empty when they are equal, so the changes of the user
+// are the added lines.
+type FileDiff struct {
+	Path      string `json:"path"`
+	Schematic string `json:"schematic,omitempty"`
+	Context   string `json:"context,omitempty"`
+	Template  string `json:"template,omitempty"`
+	Status    Status `json:"status"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+type DiffService interface {
+	Diff() ([]FileDiff, error)
+}
+
+type diffService struct {
+	File         file.File
+	Project      *project.Project
+	SchematicsFS fs.FS
+	variables    map[string][]config.Variable
+}
+
+func NewDiffService(
+	file file.File,
+	project *project.Project,
+	schematicsFS fs.FS,
+) DiffService {
+	return &diffService{
+		File:         file,
+		Project:      project,
+		SchematicsFS: schematicsFS,
+		variables:    make(map[string][]config.Variable),
+	}
+}
+
+// Diff renders every generated file of the project again, in memory, and
+// compares it with the working tree, in the order of gen.yaml.
+func (s *diffService) Diff() ([]FileDiff, error) {
+	diffs := []FileDiff{}
+	for _, generated := range s.Project.Files {
+		fileDiff := FileDiff{
+			Path:      generated.Path,
+			Schematic: generated.Schematic,
+			Context:   generated.Context,
+			Template:  generated.Template,
+		}
+		if generated.Schematic == "" || generated.Template == "" || generated.Checksum == "" {
+			fileDiff.Status = Unknown
+			diffs = append(diffs, fileDiff)
+			continue
+		}
+
+		rendered, err := s.render(generated)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", generated.Path, err)
+		}
+
+		current, err := s.File.ReadFile(generated.Path)
+		if err != nil {
+			fileDiff.Status = Missing
+			fileDiff.Diff = diff.Unified("a/"+generated.Path, "/dev/null", rendered, nil)
+			diffs = append(diffs, fileDiff)
+			continue
+		}
+
+		userModified := project.Checksum(current) != generated.Checksum
+		templateChanged := project.Checksum(rendered) != generated.TemplateChecksum
+		switch {
+		case string(current) == string(rendered):
+			fileDiff.Status = Unchanged
+		case userModified && templateChanged:
+			fileDiff.Status = Both
+		case userModified:
+			fileDiff.Status = UserModified
+		case templateChanged:
+			fileDiff.Status = TemplateChanged
+		default:
+			// edited by plugins after it was rendered, as gomakase left it
+			fileDiff.Status = Unchanged
+		}
+		if fileDiff.Status != Unchanged {
+			fileDiff.Diff = diff.Unified("a/"+generated.Path, "b/"+generated.Path, rendered, current)
+		}
+		diffs = append(diffs, fileDiff)
+	}
+	return diffs, nil
+}
+
+// render renders the template of a generated file with the data it was
+// generated with.
+func (s *diffService) render(generated project.GeneratedFile) ([]byte, error) {
+	variables, err := s.schematicVariables(generated.Schematic)
+	if err != nil {
+		return nil, err
+	}
+
+	projectData := s.Project.TemplateData()
+	contextData := map[string]string{}
+	if generated.Context != "" {
+		context, err := naming.ParseContext(generated.Context)
+		if err != nil {
+			return nil, err
+		}
+		contextData = context.Data()
+	}
+	pluginVariables := map[string]string{}
+	for _, plugin := range s.Project.Plugins {
+		if path.Join("plugins", plugin.Name) == generated.Schematic {
+			pluginVariables = plugin.Variables
+		}
+	}
+
+	// populate data for templates from the variables
+	data := make(map[string]string)
+	for _, variable := range variables {
+		data[variable.Name] = projectData[variable.Name]
+		if value, ok := contextData[variable.Name]; ok {
+			data[variable.Name] = value
+		}
+		if value, ok := pluginVariables[variable.Name]; ok {
+			data[variable.Name] = value
+		}
+		if data[variable.Name] == "" {
+			data[variable.Name] = variable.Default
+		}
+	}
+
+	content, err := schematic.ReadTemplate(s.File, s.SchematicsFS, generated.Schematic, generated.Template)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := s.File.ParseTemplate(content, data)
+	if err != nil {
+		return nil, err
+	}
+	if format.IsGoFile(generated.Path) {
+		return format.Source(generated.Template, rendered)
+	}
+	return rendered, nil
+}
+
+func (s *diffService) schematicVariables(name string) ([]config.Variable, error) {
+	if variables, ok := s.variables[name]; ok {
+		return variables, nil
+	}
+	content, err := fs.ReadFile(s.SchematicsFS, path.Join(schematic.Root, name, "schematic.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	// every schematic kind declares its variables the same way
+	loaded, err := config.LoadSchematic[config.PluginSchematic](content)
+	if err != nil {
+		return nil, err
+	}
+	s.variables[name] = loaded.Variables
+	return loaded.Variables, nil
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/parser"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/version"
+	"golang.org/x/mod/modfile"
+)
+
+const routerFile = parser.RouterFile
+
+type DoctorService interface {
+	Diagnose() []Check
+}
+
+// Check is the outcome of a single diagnosis. Err is nil when the check
+// passed, otherwise Hint tells the user how to fix it.
+type Check struct {
+	Name string
+	Err  error
+	Hint string
+}
+
+type doctorService struct {
+	File     file.File
+	LookPath func(file string) (string, error)
+}
+
+func NewDoctorService(
+	file file.File,
+	lookPath func(file string) (string, error),
+) DoctorService {
+	return &doctorService{
+		File:     file,
+		LookPath: lookPath,
+	}
+}
+
+func (s *doctorService) Diagnose() []Check {
+	checks := []Check{}
+
+	p, err := project.Load(s.File)
+	checks = append(checks, Check{
+		Name: "gen.yaml parses",
+		Err:  err,
+		Hint: "Run gomakase inside the project or pass --project-dir, and make sure gen.yaml is valid YAML with a module key.",
+	})
+	if err == nil {
+		checks = append(checks,
+			Check{
+				Name: "gen.yaml module matches go.mod",
+				Err:  s.checkModule(p),
+				Hint: "Make the module in gen.yaml and the module line in go.mod the same, generated imports use it.",
+			},
+			Check{
+				Name: "generatorVersion is compatible",
+				Err:  version.Compatible(p.GeneratorVersion),
+				Hint: fmt.Sprintf("Install the gomakase release that generated the project: go install github.com/IrwantoCia/gomakase@v%s", strings.TrimPrefix(p.GeneratorVersion, "v")),
+			},
+			Check{
+				Name: "generated files exist",
+				Err:  s.checkFiles(p),
+				Hint: "Restore the missing files from version control, or remove them from the files list in gen.yaml.",
+			},
+		)
+	}
+
+	checks = append(checks,
+		Check{
+			Name: "go is installed",
+			Err:  s.checkTool("go"),
+			Hint: "Install Go from https://go.dev/dl/ and add it to your PATH.",
+		},
+		Check{
+			Name: "npm is installed",
+			Err:  s.checkTool("npm"),
+			Hint: "Install Node.js from https://nodejs.org/ and add it to your PATH.",
+		},
+		Check{
+			Name: "air is installed",
+			Err:  s.checkTool("air"),
+			Hint: "Install air with: go install github.com/air-verse/air@latest",
+		},
+		Check{
+			Name: routerFile + " has a " + parser.RoutesFunc + " function",
+			Err:  s.checkRouter(),
+			Hint: fmt.Sprintf("Plugins add their dependencies and routes to %s. Restore func %s(router *gin.Engine, database db.Database, logger logger.Logger) in %s.", parser.RoutesFunc, parser.RoutesFunc, routerFile),
+		},
+	)
+
+	return checks
+}
+
+func (s *doctorService) checkModule(p *project.Project) error {
+	content, err := s.File.ReadFile("go.mod")
+	if err != nil {
+		return err
+	}
+	module := modfile.ModulePath(content)
+	if module == "" {
+		return fmt.Errorf("go.mod has no module line")
+	}
+	if module != p.Module {
+		return fmt.Errorf("gen.yaml has module %q but go.mod has module %q", p.Module, module)
+	}
+	return nil
+}
+
+func (s *doctorService) checkFiles(p *project.Project) error {
+	missing := []string{}
+	for _, file := range p.Files {
+		if !s.File.IsPathExists(file.Path) {
+			missing = append(missing, file.Path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (s *doctorService) checkTool(name string) error {
+	_, err := s.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("%s not found in PATH", name)
+	}
+	return nil
+}
+
+func (s *doctorService) checkRouter() error {
+	parser, err := parser.NewASTParser(s.File, routerFile)
+	if err != nil {
+		return err
+	}
+	return parser.CheckRoutes()
+}
@@ -0,0 +1,71 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
+)
+
+type EjectService interface {
+	Eject(schematicName string, template string, force bool) (string, error)
+}
+
+type ejectService struct {
+	File         file.File
+	SchematicsFS fs.FS
+}
+
+func NewEjectService(file file.File, schematicsFS fs.FS) EjectService {
+	return &ejectService{
+		File:         file,
+		SchematicsFS: schematicsFS,
+	}
+}
+
+// Eject copies a template of the schematic schematicName, e.g. context or
+// plugins/auth, to the project's override directory, where it is used
+// instead of the built-in one. It returns the path of the copy, and refuses
+// to overwrite an existing override unless force is set.
+func (s *ejectService) Eject(schematicName string, template string, force bool) (string, error) {
+	if !fs.ValidPath(schematicName) || !fs.ValidPath(template) {
+		return "", fmt.Errorf("invalid template %s/%s", schematicName, template)
+	}
+
+	content, err := fs.ReadFile(s.SchematicsFS, schematic.TemplatePath(schematicName, template))
+	if errors.Is(err, fs.ErrNotExist) {
+		templates, listErr := s.templates(schematicName)
+		if listErr != nil {
+			return "", fmt.Errorf("schematic %s not found", schematicName)
+		}
+		return "", fmt.Errorf("template %s not found in schematic %s, expected one of: %s", template, schematicName, strings.Join(templates, ", "))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	output := schematic.OverridePath(schematicName, template)
+	if !force && s.File.IsPathExists(output) {
+		return output, fmt.Errorf("%s already exists, pass --force to overwrite it", output)
+	}
+	return output, s.File.CreateFile(output, content)
+}
+
+func (s *ejectService) templates(schematicName string) ([]string, error) {
+	dir := path.Join(schematic.Root, schematicName, "templates")
+	templates := []string{}
+	err := fs.WalkDir(s.SchematicsFS, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			templates = append(templates, strings.TrimPrefix(p, dir+"/"))
+		}
+		return nil
+	})
+	return templates, err
+}
@@ -0,0 +1,376 @@
+package application
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+)
+
+// Layers are the packages of a context, in the order they depend on each
+// other, the domain first.
+var Layers = []string{"domain", "application", "delivery", "infrastructure"}
+
+// Layer is a layer of a context, internal/<context>/<layer>. The files in
+// the subdirectories of the layer belong to it too.
+type Layer struct {
+	// Context is the directory of the context under internal, e.g.
+	// billing/orderitem.
+	Context string `json:"context"`
+	// Name is one of Layers.
+	Name string `json:"layer"`
+}
+
+func (l Layer) String() string {
+	return l.Context + "/" + l.Name
+}
+
+// Import is an import of a file of a layer.
+type Import struct {
+	From Layer  `json:"from"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Path is the import path, e.g. gorm.io/gorm.
+	Path string `json:"path"`
+	// To is the layer Path is when it is a layer of the project, and nil
+	// otherwise.
+	To *Layer `json:"to,omitempty"`
+}
+
+// Graph is the layers of the contexts of a project and their imports.
+type Graph struct {
+	// Layers are sorted by context, then in the order of Layers.
+	Layers  []Layer  `json:"layers"`
+	Imports []Import `json:"imports"`
+}
+
+// Rule is a boundary between layers that imports must not cross.
+type Rule struct {
+	Name        string
+	Description string
+	// Breaks reports whether imp crosses the boundary.
+	Breaks func(imp Import) bool
+}
+
+// Rules are the boundaries of the layers gomakase generates.
+var Rules = []Rule{
+	{
+		Name:        "domain-is-pure",
+		Description: "domain must not import gorm, gin or an infrastructure layer",
+		Breaks: func(imp Import) bool {
+			return imp.From.Name == "domain" &&
+				(isPackage(imp.Path, "gorm.io") || isPackage(imp.Path, "github.com/gin-gonic/gin") ||
+					imp.To != nil && imp.To.Name == "infrastructure")
+		},
+	},
+	{
+		Name:        "application-not-delivery",
+		Description: "application must not import a delivery layer",
+		Breaks: func(imp Import) bool {
+			return imp.From.Name == "application" && imp.To != nil && imp.To.Name == "delivery"
+		},
+	},
+	{
+		Name:        "infrastructure-is-private",
+		Description: "a context must not import the infrastructure layer of another context",
+		Breaks: func(imp Import) bool {
+			return imp.To != nil && imp.To.Name == "infrastructure" && imp.To.Context != imp.From.Context
+		},
+	},
+}
+
+// Violation is an import that crosses the boundary of Rule.
+type Violation struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	Import      Import `json:"import"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d: %s imports %s, but %s (%s)",
+		v.Import.File, v.Import.Line, v.Import.From, v.Import.Path, v.Description, v.Rule)
+}
+
+type GraphService interface {
+	Graph() (*Graph, error)
+	Check() ([]Violation, error)
+}
+
+type graphService struct {
+	File    file.File
+	Project *project.Project
+}
+
+func NewGraphService(
+	file file.File,
+	project *project.Project,
+) GraphService {
+	return &graphService{
+		File:    file,
+		Project: project,
+	}
+}
+
+// Graph reads the imports of the files of the layers of every context
+// under internal, leaving out the tests.
+func (s *graphService) Graph() (*Graph, error) {
+	graph := &Graph{Layers: []Layer{}, Imports: []Import{}}
+	layerDirs := map[Layer][]string{}
+	if err := s.findLayers("internal", layerDirs); err != nil {
+		return nil, err
+	}
+	for layer := range layerDirs {
+		graph.Layers = append(graph.Layers, layer)
+	}
+	slices.SortFunc(graph.Layers, compareLayers)
+
+	for _, layer := range graph.Layers {
+		for _, dir := range layerDirs[layer] {
+			imports, err := s.imports(layer, dir)
+			if err != nil {
+				return nil, err
+			}
+			graph.Imports = append(graph.Imports, imports...)
+		}
+	}
+	return graph, nil
+}
+
+// Check returns the imports that break Rules, in the order of the graph.
+func (s *graphService) Check() ([]Violation, error) {
+	graph, err := s.Graph()
+	if err != nil {
+		return nil, err
+	}
+	return graph.Violations(), nil
+}
+
+// findLayers adds the layers under dir to layerDirs, with the directories
+// of their files: the layer and its subdirectories.
+func (s *graphService) findLayers(dir string, layerDirs map[Layer][]string) error {
+	names, err := s.File.ListDirs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	for _, name := range names {
+		child := path.Join(dir, name)
+		if !slices.Contains(Layers, name) || dir == "internal" {
+			if err := s.findLayers(child, layerDirs); err != nil {
+				return err
+			}
+			continue
+		}
+		layer := Layer{Context: strings.TrimPrefix(dir, "internal/"), Name: name}
+		dirs, err := s.subdirs(child)
+		if err != nil {
+			return err
+		}
+		layerDirs[layer] = append(layerDirs[layer], dirs...)
+	}
+	return nil
+}
+
+// subdirs returns dir and the directories under it.
+func (s *graphService) subdirs(dir string) ([]string, error) {
+	dirs := []string{dir}
+	names, err := s.File.ListDirs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	for _, name := range names {
+		children, err := s.subdirs(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, children...)
+	}
+	return dirs, nil
+}
+
+// imports returns the imports of the Go files of dir, a directory of
+// layer.
+func (s *graphService) imports(layer Layer, dir string) ([]Import, error) {
+	names, err := s.File.ListFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	imports := []Import{}
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		filePath := path.Join(dir, name)
+		content, err := s.File.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		fset := token.NewFileSet()
+		goFile, err := parser.ParseFile(fset, filePath, content, parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		for _, importSpec := range goFile.Imports {
+			importPath, err := strconv.Unquote(importSpec.Path.Value)
+			if err != nil {
+				continue
+			}
+			imports = append(imports, Import{
+				From: layer,
+				File: filePath,
+				Line: fset.Position(importSpec.Pos()).Line,
+				Path: importPath,
+				To:   s.layerOf(importPath),
+			})
+		}
+	}
+	return imports, nil
+}
+
+// layerOf returns the layer of the project importPath is in, or nil.
+func (s *graphService) layerOf(importPath string) *Layer {
+	rest, ok := strings.CutPrefix(importPath, s.Project.Module+"/internal/")
+	if !ok {
+		return nil
+	}
+	parts := strings.Split(rest, "/")
+	for i, part := range parts {
+		if i > 0 && slices.Contains(Layers, part) {
+			return &Layer{Context: strings.Join(parts[:i], "/"), Name: part}
+		}
+	}
+	return nil
+}
+
+// Violations returns the imports of the graph that break Rules.
+func (g *Graph) Violations() []Violation {
+	violations := []Violation{}
+	for _, imp := range g.Imports {
+		for _, rule := range Rules {
+			if rule.Breaks(imp) {
+				violations = append(violations, Violation{Rule: rule.Name, Description: rule.Description, Import: imp})
+			}
+		}
+	}
+	return violations
+}
+
+// edge is a dependency of a layer on another.
+type edge struct {
+	From, To Layer
+	// Violation is whether one of the imports of the dependency breaks a
+	// rule.
+	Violation bool
+}
+
+// edges returns the dependencies between the layers, each once, in the
+// order of the graph.
+func (g *Graph) edges() []edge {
+	edges := []edge{}
+	index := map[[2]Layer]int{}
+	for _, imp := range g.Imports {
+		if imp.To == nil || *imp.To == imp.From {
+			continue
+		}
+		broken := slices.ContainsFunc(Rules, func(rule Rule) bool { return rule.Breaks(imp) })
+		key := [2]Layer{imp.From, *imp.To}
+		if i, ok := index[key]; ok {
+			edges[i].Violation = edges[i].Violation || broken
+			continue
+		}
+		index[key] = len(edges)
+		edges = append(edges, edge{From: imp.From, To: *imp.To, Violation: broken})
+	}
+	return edges
+}
+
+// contexts returns the contexts of the layers, in order, with their layers.
+func (g *Graph) contexts() ([]string, map[string][]Layer) {
+	contexts := []string{}
+	layers := map[string][]Layer{}
+	for _, layer := range g.Layers {
+		if _, ok := layers[layer.Context]; !ok {
+			contexts = append(contexts, layer.Context)
+		}
+		layers[layer.Context] = append(layers[layer.Context], layer)
+	}
+	return contexts, layers
+}
+
+// Mermaid renders the graph as a Mermaid flowchart with a subgraph per
+// context. The dependencies that break a rule are red.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	contexts, layers := g.contexts()
+	for _, context := range contexts {
+		fmt.Fprintf(&b, "  subgraph cluster_%s[%q]\n", nodeID(context), context)
+		for _, layer := range layers[context] {
+			fmt.Fprintf(&b, "    %s[%q]\n", nodeID(layer.String()), layer.Name)
+		}
+		b.WriteString("  end\n")
+	}
+	broken := []string{}
+	for i, e := range g.edges() {
+		fmt.Fprintf(&b, "  %s --> %s\n", nodeID(e.From.String()), nodeID(e.To.String()))
+		if e.Violation {
+			broken = append(broken, strconv.Itoa(i))
+		}
+	}
+	if len(broken) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red\n", strings.Join(broken, ","))
+	}
+	return b.String()
+}
+
+// DOT renders the graph as a Graphviz digraph with a cluster per context.
+// The dependencies that break a rule are red.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph contexts {\n  rankdir=LR;\n  node [shape=box];\n")
+	contexts, layers := g.contexts()
+	for _, context := range contexts {
+		fmt.Fprintf(&b, "  subgraph cluster_%s {\n    label=%q;\n", nodeID(context), context)
+		for _, layer := range layers[context] {
+			fmt.Fprintf(&b, "    %s [label=%q];\n", nodeID(layer.String()), layer.Name)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, e := range g.edges() {
+		attrs := ""
+		if e.Violation {
+			attrs = " [color=red]"
+		}
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", nodeID(e.From.String()), nodeID(e.To.String()), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nodeID returns name with the characters Mermaid and DOT identifiers
+// cannot have replaced, e.g. billing_orderitem_domain.
+func nodeID(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// isPackage reports whether importPath is pkg or a package under it.
+func isPackage(importPath string, pkg string) bool {
+	return importPath == pkg || strings.HasPrefix(importPath, pkg+"/")
+}
+
+func compareLayers(a, b Layer) int {
+	if a.Context != b.Context {
+		return strings.Compare(a.Context, b.Context)
+	}
+	return slices.Index(Layers, a.Name) - slices.Index(Layers, b.Name)
+}
@@ -1,54 +1,67 @@
 package application
 
 import (
-	"embed"
 	"errors"
-	"log"
+	"fmt"
+	"io/fs"
 	"path"
 
-	SEmbed "github.com/IrwantoCia/gomakase/embed"
 	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
 	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/format"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/version"
 )
 
 type NewService interface {
-	Generate(name string, schematic config.ProjectSchematic) error
+	Generate(project *project.Project, schematic config.ProjectSchematic) error
 }
 
-func NewNewService(file file.File) NewService {
+// NewNewService returns a NewService that renders the project schematic from
+// schematicsFS into file, which is rooted at the new project's directory.
+// Progress is reported to events.
+func NewNewService(file file.File, schematicsFS fs.FS, events event.Emitter) NewService {
 	return &newService{
-		EmbedFS: SEmbed.SchematicsFS,
-		File:    file,
+		SchematicsFS: schematicsFS,
+		File:         file,
+		Events:       events,
 	}
 }
 
 type newService struct {
-	EmbedFS embed.FS
-	File    file.File
+	SchematicsFS fs.FS
+	File         file.File
+	Events       event.Emitter
 }
 
 type Job struct {
 	OutputPath string
+	Template   string
 	Content    []byte
 }
 
-func (s newService) Generate(name string, schematic config.ProjectSchematic) error {
-	log.Printf("Generating a new project: %s\n", name)
-
-	if s.File.IsPathExists(name) {
-		log.Printf("Project already exists, skipping...\n")
+func (s newService) Generate(p *project.Project, schematic config.ProjectSchematic) error {
+	// an existing directory is fine as long as no generated file is in it
+	if s.File.IsPathExists(project.FileName) {
+		s.Events.Emit(event.Event{
+			Type:   event.FileSkipped,
+			Path:   project.FileName,
+			Reason: "project already exists",
+		})
 		return nil
 	}
 
+	p.GeneratorVersion = version.Version
+
 	variables := schematic.Variables
+	projectData := p.TemplateData()
 	// populate data for templates from the variables
 	data := make(map[string]string)
 	for _, variable := range variables {
-		switch variable.Name {
-		case "Module":
-			data[variable.Name] = name
-		default:
-			data[variable.Name] = ""
+		data[variable.Name] = projectData[variable.Name]
+		if data[variable.Name] == "" {
+			data[variable.Name] = variable.Default
 		}
 	}
 
@@ -56,12 +69,13 @@ func (s newService) Generate(name string, schematic config.ProjectSchematic) err
 	jobError := false
 	for _, action := range schematic.Actions {
 		if s.File.IsPathExists(action.Output) {
-			log.Printf("Path already exists, skipping...\n")
+			s.Events.Emit(event.Event{Type: event.Error, Path: action.Output, Error: "path already exists"})
 			jobError = true
 			continue
 		}
 
-		content, err := s.EmbedFS.ReadFile(
+		content, err := fs.ReadFile(
+			s.SchematicsFS,
 			path.Join(
 				"schematics",
 				"project",
@@ -69,7 +83,7 @@ func (s newService) Generate(name string, schematic config.ProjectSchematic) err
 				action.Template,
 			))
 		if err != nil {
-			log.Printf("Error reading template file...\n%v", err)
+			s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
 			jobError = true
 			continue
 		}
@@ -77,37 +91,69 @@ func (s newService) Generate(name string, schematic config.ProjectSchematic) err
 		// parse the output path
 		outputPath, err := s.File.ParseFilePath(action.Output, data)
 		if err != nil {
-			log.Printf("Error parsing output path...\n%v", err)
+			s.Events.Emit(event.Event{
+				Type:     event.Error,
+				Template: action.Template,
+				Error:    fmt.Sprintf("parsing output path: %v", err),
+			})
 			jobError = true
 			continue
 		}
 
 		parsedContent, err := s.File.ParseTemplate(content, data)
 		if err != nil {
-			log.Printf("Error parsing template...\n%v", err)
+			s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
 			jobError = true
 			continue
 		}
 
+		if format.IsGoFile(outputPath) {
+			parsedContent, err = format.Source(action.Template, parsedContent)
+			if err != nil {
+				s.Events.Emit(event.Event{Type: event.Error, Template: action.Template, Error: err.Error()})
+				jobError = true
+				continue
+			}
+		}
+
 		jobs = append(jobs, Job{
 			OutputPath: outputPath,
+			Template:   action.Template,
 			Content:    parsedContent,
 		})
 
 	}
 
 	if jobError {
-		log.Printf("Job error, skipping...\n")
 		return errors.New("job error")
 	}
 
+	// describe the generated project in its descriptor
+	for _, job := range jobs {
+		p.AddFiles(project.GeneratedFile{
+			Path:             job.OutputPath,
+			Schematic:        "project",
+			Template:         job.Template,
+			TemplateChecksum: project.Checksum(job.Content),
+			Checksum:         project.Checksum(job.Content),
+		})
+	}
+	descriptor, err := p.Marshal()
+	if err != nil {
+		return fmt.Errorf("writing project descriptor: %w", err)
+	}
+	jobs = append(jobs, Job{
+		OutputPath: project.FileName,
+		Content:    descriptor,
+	})
+
 	for _, job := range jobs {
-		log.Printf("Creating file: %s\n", job.OutputPath)
 		err := s.File.CreateFile(job.OutputPath, job.Content)
 		if err != nil {
-			log.Printf("Error creating file %s: %v\n\n", job.OutputPath, err)
+			s.Events.Emit(event.Event{Type: event.Error, Path: job.OutputPath, Error: err.Error()})
 			continue
 		}
+		s.Events.Emit(event.Event{Type: event.FileCreated, Path: job.OutputPath, Template: job.Template})
 	}
 
 	return nil
@@ -0,0 +1,143 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/naming"
+	"github.com/IrwantoCia/gomakase/internal/shared/parser"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+)
+
+// AuthMiddleware is the call of the auth plugin's middleware that protected
+// groups use, without the package. It needs the authService variable the
+// plugin adds to Routes.
+const AuthMiddleware = "AuthMiddleware(&authService)"
+
+type RouteService interface {
+	Protect(prefix string) error
+	List() ([]parser.Route, error)
+}
+
+type routeService struct {
+	Project *project.Project
+	File    file.File
+	Events  event.Emitter
+}
+
+func NewRouteService(
+	file file.File,
+	project *project.Project,
+	events event.Emitter,
+) RouteService {
+	return &routeService{
+		Project: project,
+		File:    file,
+		Events:  events,
+	}
+}
+
+// Protect moves the routes under prefix in the router file into a group
+// with the auth plugin's middleware, creating the group when it does not
+// exist yet. An existing group with prefix that does not have the
+// middleware is an error, as moving the routes into it would leave them
+// unauthenticated.
+func (s *routeService) Protect(prefix string) error {
+	if !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("path prefix %q must start with /", prefix)
+	}
+	// /product/ is the prefix /product, whose routes keep their paths
+	if prefix != "/" {
+		prefix = strings.TrimSuffix(prefix, "/")
+	}
+	if !s.Project.HasPlugin("auth") {
+		return errors.New("protecting routes needs the auth plugin, add it with gomakase add auth")
+	}
+
+	routerParser, err := parser.NewASTParser(s.File, parser.RouterFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", parser.RouterFile, err)
+	}
+
+	// a group without routes would not compile, so the file is only
+	// written when there is something to move into it
+	group, existed := routerParser.FindRouteGroup(parser.RouteTarget, prefix)
+	name := group.Name
+	if existed && !slices.ContainsFunc(group.Middlewares, isAuthMiddleware) {
+		return fmt.Errorf("the group %s of %s in %s has no %s, add it to the group to protect its routes",
+			name, prefix, parser.RouterFile, AuthMiddleware)
+	}
+	if !existed {
+		middleware, _, err := routerParser.AddImport(s.Project.Module+"/internal/shared/middleware", "")
+		if err != nil {
+			return err
+		}
+		name, _, err = routerParser.AddRouteGroup(parser.RouteTarget, parser.RouteGroup{
+			Name:        groupName(prefix),
+			Prefix:      prefix,
+			Middlewares: []string{middleware + "." + AuthMiddleware},
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", parser.RouterFile, err)
+		}
+	}
+
+	moved, err := routerParser.MoveRoutes(parser.RouteTarget, prefix, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", parser.RouterFile, err)
+	}
+	if len(moved) == 0 && existed {
+		s.Events.Emit(event.Event{Type: event.Satisfied, Action: "protect", Detail: prefix})
+		return nil
+	}
+	if len(moved) == 0 {
+		return fmt.Errorf("no routes under %s in %s", prefix, parser.RouterFile)
+	}
+
+	err = routerParser.WriteFile()
+	if err != nil {
+		return fmt.Errorf("%s: %w", parser.RouterFile, err)
+	}
+	for _, route := range moved {
+		s.Events.Emit(event.Event{
+			Type:   event.ASTEdit,
+			Path:   parser.RouterFile,
+			Action: "protect_route",
+			Detail: route,
+		})
+	}
+
+	content, err := s.File.ReadFile(parser.RouterFile)
+	if err == nil {
+		s.Project.RecordWrite(parser.RouterFile, content)
+	}
+	return project.Save(s.File, s.Project)
+}
+
+// List returns the routes registered in the router file and in the
+// functions it passes the router or a group to.
+func (s *routeService) List() ([]parser.Route, error) {
+	return parser.ListRoutes(s.File, s.Project.Module)
+}
+
+// isAuthMiddleware reports whether the middleware expression calls the auth
+// plugin's middleware, whatever the package is imported as and the service
+// is named.
+func isAuthMiddleware(middleware string) bool {
+	name, _, _ := strings.Cut(AuthMiddleware, "(")
+	return strings.HasPrefix(middleware, name+"(") || strings.Contains(middleware, "."+name+"(")
+}
+
+// groupName returns the variable of the group for prefix, e.g. adminGroup
+// for /admin.
+func groupName(prefix string) string {
+	name := naming.Camel(strings.ReplaceAll(prefix, "/", "_"))
+	if name == "" {
+		return "protected"
+	}
+	return name + "Group"
+}
@@ -0,0 +1,123 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+)
+
+// Supported archive formats, chosen by the extension of the archive name.
+const (
+	TarGz = "tar.gz"
+	Zip   = "zip"
+)
+
+// Format returns the archive format for name, or an error when its extension
+// is not supported.
+func Format(name string) (string, error) {
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return TarGz, nil
+	case strings.HasSuffix(name, ".zip"):
+		return Zip, nil
+	}
+	return "", fmt.Errorf("unsupported archive %s, expected a .tar.gz, .tgz or .zip file", name)
+}
+
+// Write writes every file of files to w as an archive in format. The files
+// are placed under dir, so extracting the archive creates that directory.
+func Write(w io.Writer, format string, files *file.MemFS, dir string) error {
+	switch format {
+	case TarGz:
+		return writeTarGz(w, files, dir)
+	case Zip:
+		return writeZip(w, files, dir)
+	}
+	return fmt.Errorf("unsupported archive format %q", format)
+}
+
+// entryName returns the name of the entry of the file name under dir. A
+// name that is absolute or leaves dir is an error, so extracting the
+// archive cannot write outside the directory it creates.
+func entryName(dir string, name string) (string, error) {
+	entry := path.Join(dir, name)
+	prefix := path.Clean(dir) + "/"
+	if dir == "" || dir == "." {
+		prefix = ""
+	}
+	if !filepath.IsLocal(filepath.FromSlash(name)) || !filepath.IsLocal(filepath.FromSlash(entry)) ||
+		!strings.HasPrefix(entry, prefix) {
+		return "", fmt.Errorf("archive entry %s is outside %s", path.Join(dir, name), dir)
+	}
+	return entry, nil
+}
+
+func writeTarGz(w io.Writer, files *file.MemFS, dir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	modTime := time.Now()
+	for _, name := range files.Paths() {
+		entry, err := entryName(dir, name)
+		if err != nil {
+			return err
+		}
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     entry,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  modTime,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeZip(w io.Writer, files *file.MemFS, dir string) error {
+	zw := zip.NewWriter(w)
+	modTime := time.Now()
+	for _, name := range files.Paths() {
+		entry, err := entryName(dir, name)
+		if err != nil {
+			return err
+		}
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		header := &zip.FileHeader{
+			Name:     entry,
+			Method:   zip.Deflate,
+			Modified: modTime,
+		}
+		header.SetMode(0644)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,118 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func newFiles(t *testing.T) *file.MemFS {
+	t.Helper()
+	files := file.NewMemFS()
+	files.WriteFile("go.mod", []byte("module demo\n"))
+	files.WriteFile("cmd/server/main.go", []byte("package main\n"))
+	return files
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "myapp.tar.gz", expected: TarGz},
+		{input: "dist/myapp.tgz", expected: TarGz},
+		{input: "myapp.zip", expected: Zip},
+	}
+	for _, tt := range tests {
+		format, err := Format(tt.input)
+		if err != nil {
+			t.Fatalf("Error reading format of %s: %v", tt.input, err)
+		}
+		assert.Equal(t, format, tt.expected)
+	}
+
+	if _, err := Format("myapp.rar"); err == nil {
+		t.Fatalf("Expected an error for an unsupported archive")
+	}
+}
+
+func TestWrite_TarGz(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, TarGz, newFiles(t), "myapp"); err != nil {
+		t.Fatalf("Error writing archive: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Error reading archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	contents := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error reading archive: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		contents[header.Name] = string(content)
+	}
+	assert.Equal(t, contents, map[string]string{
+		"myapp/cmd/server/main.go": "package main\n",
+		"myapp/go.mod":             "module demo\n",
+	})
+}
+
+func TestWrite_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Zip, newFiles(t), "myapp"); err != nil {
+		t.Fatalf("Error writing archive: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Error reading archive: %v", err)
+	}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(content)
+	}
+	assert.Equal(t, contents, map[string]string{
+		"myapp/cmd/server/main.go": "package main\n",
+		"myapp/go.mod":             "module demo\n",
+	})
+}
+
+func TestWrite_Escaping(t *testing.T) {
+	for _, format := range []string{TarGz, Zip} {
+		for _, dir := range []string{"../evil", "/tmp/evil", "myapp/../.."} {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, newFiles(t), dir); err == nil {
+				t.Fatalf("Expected an error for the %s entries under %s", format, dir)
+			}
+		}
+	}
+	// the entry names are checked too
+	if _, err := entryName("myapp", "../evil.sh"); err == nil {
+		t.Fatalf("Expected an error for an entry leaving the directory")
+	}
+	entry, err := entryName("myapp", "cmd/server/main.go")
+	if err != nil {
+		t.Fatalf("Error naming entry: %v", err)
+	}
+	assert.Equal(t, entry, "myapp/cmd/server/main.go")
+}
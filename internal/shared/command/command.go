@@ -3,43 +3,60 @@ package command
 import (
 	"os"
 	"os/exec"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
 )
 
-type Command interface {
-	GoModTidy() error
-	ChangeFolder(path string) error
-	NPMInstall() error
-	GoFmt() error
-}
+// Hook is a command run in the project directory after a generation.
+type Hook []string
+
+var (
+	GoModTidy  = Hook{"go", "mod", "tidy"}
+	NPMInstall = Hook{"npm", "install"}
+)
 
-type command struct {
+func (h Hook) String() string {
+	return strings.Join(h, " ")
 }
 
-func NewCommand() Command {
-	return &command{}
+// Runner runs hooks.
+type Runner interface {
+	Run(name string, args ...string) error
 }
 
-func (c *command) GoModTidy() error {
-	cmd := exec.Command("go", "mod", "tidy")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+type runner struct {
+	dir string
 }
 
-func (c *command) ChangeFolder(path string) error {
-	return os.Chdir(path)
+// NewRunner returns a Runner that executes hooks in dir, usually the
+// project root. An empty dir runs them in the working directory. Their
+// output goes to stderr, so it never mixes with the events on stdout.
+func NewRunner(dir string) Runner {
+	return &runner{dir: dir}
 }
 
-func (c *command) NPMInstall() error {
-	cmd := exec.Command("npm", "install")
-	cmd.Stdout = os.Stdout
+func (r *runner) Run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = r.dir
+	cmd.Stdout = os.Stderr
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-func (c *command) GoFmt() error {
-	cmd := exec.Command("go", "fmt", "./...")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+// RunHooks runs hooks in order with runner and reports them to events. It
+// stops at the first hook that fails. A nil runner runs nothing.
+func RunHooks(runner Runner, events event.Emitter, hooks ...Hook) error {
+	if runner == nil {
+		return nil
+	}
+	for _, hook := range hooks {
+		events.Emit(event.Event{Type: event.HookStarted, Hook: hook.String()})
+		err := runner.Run(hook[0], hook[1:]...)
+		if err != nil {
+			events.Emit(event.Event{Type: event.HookFailed, Hook: hook.String(), Error: err.Error()})
+			return err
+		}
+	}
+	return nil
 }
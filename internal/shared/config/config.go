@@ -2,7 +2,7 @@ package config
 
 import (
 	"bytes"
-	"log"
+	"fmt"
 
 	"github.com/spf13/viper"
 )
@@ -10,6 +10,7 @@ import (
 type Variable struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+	Default     string `yaml:"default"`
 }
 
 type ProjectAction struct {
@@ -27,6 +28,17 @@ type ContextAction struct {
 	Type     string `yaml:"type"`
 	Template string `yaml:"template"`
 	Output   string `yaml:"output"`
+	// Import, Alias and Variable are the import of add_import, and the
+	// package of add_migration. Variable names the template variable that
+	// gets the name the package was imported as.
+	Import   string `yaml:"import"`
+	Alias    string `yaml:"alias"`
+	Variable string `yaml:"variable"`
+	// Dependency, Route and Schema are the snippets of add_dependency,
+	// add_route and add_migration, rendered after the imports.
+	Dependency string `yaml:"dependency"`
+	Route      string `yaml:"route"`
+	Schema     string `yaml:"schema"`
 }
 type ContextSchematic struct {
 	Description string          `yaml:"description"`
@@ -35,35 +47,69 @@ type ContextSchematic struct {
 }
 
 type PluginAction struct {
-	Type       string `yaml:"type"`
-	Template   string `yaml:"template"`
-	Output     string `yaml:"output"`
-	File       string `yaml:"file"`
-	Import     string `yaml:"import"`
-	Alias      string `yaml:"alias"`
+	Type     string `yaml:"type"`
+	Template string `yaml:"template"`
+	Output   string `yaml:"output"`
+	File     string `yaml:"file"`
+	Import   string `yaml:"import"`
+	Alias    string `yaml:"alias"`
+	// Variable names the template variable that gets the name add_import
+	// imported the package as, for the snippets of later actions.
+	Variable   string `yaml:"variable"`
 	Dependency string `yaml:"dependency"`
 	Route      string `yaml:"route"`
+	Statement  string `yaml:"statement"`
+	// Schema is the type add_migration registers from the package of
+	// Import, e.g. UserSchema.
+	Schema string `yaml:"schema"`
+	// Group, Prefix, Middlewares and Routes describe the group of
+	// add_route_group. Routes are written without the group variable, as
+	// in GET("/profile", handler.Profile).
+	Group       string   `yaml:"group"`
+	Prefix      string   `yaml:"prefix"`
+	Middlewares []string `yaml:"middlewares"`
+	Routes      []string `yaml:"routes"`
+	// Section and Settings are the section add_config adds to AppConfig,
+	// and Env is the environment file that documents its settings.
+	Section  string    `yaml:"section"`
+	Settings []Setting `yaml:"settings"`
+	Env      string    `yaml:"env"`
+	// Function, Receiver, Anchor and Match select where add_dependency,
+	// add_route and add_statement insert their code in the output file.
+	Function string `yaml:"function"`
+	Receiver string `yaml:"receiver"`
+	Anchor   string `yaml:"anchor"`
+	Match    string `yaml:"match"`
+}
+
+// Setting is a field of the section of add_config, such as
+// Secret string set by JWT.JWT_SECRET.
+type Setting struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Key         string `yaml:"key"`
+	Default     string `yaml:"default"`
+	Description string `yaml:"description"`
 }
+
 type PluginSchematic struct {
 	Description string         `yaml:"description"`
+	Version     string         `yaml:"version"`
 	Variables   []Variable     `yaml:"variables"`
 	Actions     []PluginAction `yaml:"actions"`
 }
 
-type RootSchematic struct {
-	Module           string `yaml:"module"`
-	GeneratorVersion string `yaml:"generatorVersion"`
-}
-
 func LoadSchematic[T any](configFileContent []byte) (T, error) {
 	var config T
 
 	viper.SetConfigType("yaml")
-	viper.ReadConfig(bytes.NewBuffer(configFileContent))
-	err := viper.Unmarshal(&config)
+	err := viper.ReadConfig(bytes.NewBuffer(configFileContent))
+	if err != nil {
+		return config, fmt.Errorf("error reading config file: %w", err)
+	}
+	err = viper.Unmarshal(&config)
 	if err != nil {
-		log.Fatalf("Error unmarshalling config file: %v", err)
-		return config, err
+		return config, fmt.Errorf("error unmarshalling config file: %w", err)
 	}
 
 	return config, nil
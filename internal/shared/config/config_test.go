@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestConfig_LoadSchematic(t *testing.T) {
+	input := `description: "Adds authentication functionality to the project."
+version: "1.0.0"
+variables:
+  - name: Module
+actions:
+  - type: add_import
+    output: "cmd/server/router.go"
+    import: "{{ .Module }}/internal/auth/application"
+    alias: "authApp"
+`
+
+	plugin, err := LoadSchematic[PluginSchematic]([]byte(input))
+	if err != nil {
+		t.Fatalf("Error loading schematic: %v", err)
+	}
+	assert.Equal(t, plugin.Version, "1.0.0")
+	assert.Equal(t, len(plugin.Actions), 1)
+	assert.Equal(t, plugin.Actions[0].Alias, "authApp")
+}
+
+func TestConfig_LoadSchematic_Invalid(t *testing.T) {
+	_, err := LoadSchematic[PluginSchematic]([]byte("version: [1.0.0"))
+	if err == nil {
+		t.Fatalf("Expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// UserConfig holds the user's defaults for every project they generate. It is
+// read from $XDG_CONFIG_HOME/gomakase/config.yaml.
+type UserConfig struct {
+	// ModulePrefix is prepended to project names without a slash, so
+	// "myapp" becomes "<ModulePrefix>/myapp".
+	ModulePrefix  string            `yaml:"modulePrefix"`
+	Author        string            `yaml:"author"`
+	License       string            `yaml:"license"`
+	Options       UserOptions       `yaml:"options"`
+	Variables     map[string]string `yaml:"variables"`
+	SchematicDirs []string          `yaml:"schematicDirs"`
+}
+
+// UserOptions are the preferred project options, used when the matching
+// flag is not given.
+type UserOptions struct {
+	Database      string `yaml:"database"`
+	Frontend      string `yaml:"frontend"`
+	HTTPFramework string `yaml:"httpFramework"`
+}
+
+// UserConfigPath returns the default location of the user config file.
+func UserConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gomakase", "config.yaml"), nil
+}
+
+// LoadUserConfig reads the user config file at path. A missing file is not
+// an error and results in an empty config. Schematic directories starting
+// with ~ are expanded to the home directory, and relative ones are resolved
+// against the directory of the config file.
+func LoadUserConfig(path string) (UserConfig, error) {
+	var userConfig UserConfig
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return userConfig, nil
+	}
+	if err != nil {
+		return userConfig, err
+	}
+
+	err = yaml.Unmarshal(content, &userConfig)
+	if err != nil {
+		return userConfig, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	for i, dir := range userConfig.SchematicDirs {
+		if rest, ok := strings.CutPrefix(dir, "~"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return userConfig, err
+			}
+			dir = filepath.Join(home, rest)
+		} else if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		userConfig.SchematicDirs[i] = dir
+	}
+
+	return userConfig, nil
+}
+
+// Module returns the module path for a project name, adding the module
+// prefix when the name is not already a module path.
+func (c UserConfig) Module(name string) string {
+	if c.ModulePrefix == "" || strings.Contains(name, "/") {
+		return name
+	}
+	return strings.TrimSuffix(c.ModulePrefix, "/") + "/" + name
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestUserConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	path, err := UserConfigPath()
+	if err != nil {
+		t.Fatalf("Error getting user config path: %v", err)
+	}
+	assert.Equal(t, path, "/tmp/xdg/gomakase/config.yaml")
+}
+
+func TestLoadUserConfig(t *testing.T) {
+	input := `modulePrefix: github.com/ourorg
+author: Jane Doe
+license: MIT
+options:
+  database: postgres
+variables:
+  Team: payments
+schematicDirs:
+  - schematics
+  - /opt/gomakase
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("Error writing config: %v", err)
+	}
+
+	userConfig, err := LoadUserConfig(path)
+	if err != nil {
+		t.Fatalf("Error loading user config: %v", err)
+	}
+	assert.Equal(t, userConfig.Author, "Jane Doe")
+	assert.Equal(t, userConfig.License, "MIT")
+	assert.Equal(t, userConfig.Options.Database, "postgres")
+	assert.Equal(t, userConfig.Variables["Team"], "payments")
+	assert.Equal(t, userConfig.SchematicDirs, []string{filepath.Join(filepath.Dir(path), "schematics"), "/opt/gomakase"})
+}
+
+func TestLoadUserConfig_Missing(t *testing.T) {
+	userConfig, err := LoadUserConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error for a missing config, got: %v", err)
+	}
+	assert.Equal(t, userConfig.ModulePrefix, "")
+}
+
+func TestUserConfig_Module(t *testing.T) {
+	userConfig := UserConfig{ModulePrefix: "github.com/ourorg/"}
+
+	assert.Equal(t, userConfig.Module("myapp"), "github.com/ourorg/myapp")
+	assert.Equal(t, userConfig.Module("github.com/acme/billing"), "github.com/acme/billing")
+	assert.Equal(t, UserConfig{}.Module("myapp"), "myapp")
+}
@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns the unified diff from from to to, with fromName and toName
+// in the file headers. It returns an empty string when they are equal.
+func Unified(fromName, toName string, from, to []byte) string {
+	if string(from) == string(to) {
+		return ""
+	}
+	ops := edits(lines(string(from)), lines(string(to)))
+
+	// line numbers in from and to before each op
+	fromLine := make([]int, len(ops)+1)
+	toLine := make([]int, len(ops)+1)
+	for i, o := range ops {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if o.kind != '+' {
+			fromLine[i+1]++
+		}
+		if o.kind != '-' {
+			toLine[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(0, i-context)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		stop := min(len(ops), end+context+1)
+
+		fromCount := fromLine[stop] - fromLine[start]
+		toCount := toLine[stop] - toLine[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(fromLine[start], fromCount), hunkRange(toLine[start], toCount))
+		for _, o := range ops[start:stop] {
+			b.WriteByte(o.kind)
+			b.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return b.String()
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line+1)
+	}
+	return fmt.Sprintf("%d,%d", line+1, count)
+}
+
+func lines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edits returns the shortest edit script from a to b, using the longest
+// common subsequence of their lines.
+func edits(a, b []string) []op {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := []op{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
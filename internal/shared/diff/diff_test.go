@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected string
+	}{
+		{
+			name:     "equal",
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "change",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:   "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			expected: `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,3 +9,4 @@
+ 9
+ 10
+ 11
++12
+`,
+		},
+		{
+			name: "new file",
+			from: "",
+			to:   "package main\n",
+			expected: `--- a
++++ b
+@@ -0,0 +1 @@
++package main
+`,
+		},
+		{
+			name: "no newline at end",
+			from: "a\n",
+			to:   "a",
+			expected: `--- a
++++ b
+@@ -1 +1 @@
+-a
++a
+\ No newline at end of file
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, Unified("a", "b", []byte(tt.from), []byte(tt.to)), tt.expected)
+		})
+	}
+}
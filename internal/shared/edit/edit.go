@@ -0,0 +1,380 @@
+// Package edit runs the actions of a schematic that edit existing files of
+// a project, such as add_import or add_route. The edits of a file are made
+// on one parser and written once, after every action ran and every edited
+// file validated, so a failed action leaves all the files as they were.
+package edit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/parser"
+)
+
+// Action is an edit of the file Output. The paths are rendered; the
+// snippets, Dependency, Route, Statement, Schema, the middlewares of Group
+// and Routes, are rendered when the action runs, so they can refer to the
+// names earlier add_import actions chose.
+type Action struct {
+	Type   string
+	Output string
+	// Import, Alias and Variable are the import of add_import and the
+	// package of add_migration. Variable names the template variable that
+	// gets the name the package was imported as.
+	Import   string
+	Alias    string
+	Variable string
+	// Dependency, Route and Statement are the code of add_dependency,
+	// add_route and add_statement, inserted at Target.
+	Dependency string
+	Route      string
+	Statement  string
+	Target     parser.Target
+	// Schema is the type add_migration registers from the package of
+	// Import.
+	Schema string
+	// Group and Routes are the group of add_route_group and the routes
+	// added to it, without the group variable.
+	Group  parser.RouteGroup
+	Routes []string
+	// Section is the section of add_config, and Env the environment file
+	// that documents its settings.
+	Section parser.ConfigSection
+	Env     string
+}
+
+// Recorder is told the content of every file a Batch writes, such as the
+// project descriptor, which keeps the checksums of the generated files.
+type Recorder interface {
+	RecordWrite(path string, content []byte)
+}
+
+// Batch runs actions and writes the files they edited. Every event of an
+// action, its errors too, is emitted to the emitter of the batch, the
+// edits once their file is written.
+type Batch struct {
+	files  file.File
+	events event.Emitter
+	// data is the template data of the snippets, with the names chosen by
+	// add_import actions.
+	data map[string]string
+	// edited are the files the actions edit, in the order they were first
+	// edited.
+	edited []*fileEdits
+}
+
+// fileEdits are the edits of one file and the events that report them once
+// the file is written. Go files are edited through parser, other files,
+// such as .env.example, as text.
+type fileEdits struct {
+	path   string
+	parser parser.ASTParser
+	text   []byte
+	events []event.Event
+}
+
+// NewBatch returns a Batch that edits files and renders the snippets with
+// data. The names add_import chooses are added to data.
+func NewBatch(files file.File, events event.Emitter, data map[string]string) *Batch {
+	return &Batch{
+		files:  files,
+		events: events,
+		data:   data,
+	}
+}
+
+// Run runs action on the edits of its file, which is read on its first
+// edit. Nothing is written until Write.
+func (b *Batch) Run(action Action) error {
+	var err error
+	switch action.Type {
+	case "add_import":
+		err = b.addImport(action)
+	case "add_dependency":
+		err = b.addStatement(action, action.Dependency)
+	case "add_route":
+		err = b.addStatement(action, action.Route)
+	case "add_statement":
+		err = b.addStatement(action, action.Statement)
+	case "add_route_group":
+		err = b.addRouteGroup(action)
+	case "add_migration":
+		err = b.addMigration(action)
+	case "add_config":
+		err = b.addConfig(action)
+	default:
+		err = fmt.Errorf("unknown action type: %s", action.Type)
+	}
+	if err != nil {
+		b.events.Emit(event.Event{Type: event.Error, Path: action.Output, Error: err.Error()})
+	}
+	return err
+}
+
+// Write validates the edited files and writes them, tells recorder their
+// contents and emits the events of their edits. No file is written when
+// the edits of one do not validate, and files with no edits are not
+// written at all.
+func (b *Batch) Write(recorder Recorder) error {
+	contents := [][]byte{}
+	for _, edits := range b.edited {
+		// files whose actions were all satisfied are left as they are
+		if len(edits.events) == 0 {
+			contents = append(contents, nil)
+			continue
+		}
+		if edits.parser == nil {
+			contents = append(contents, edits.text)
+			continue
+		}
+		content, err := edits.parser.Content()
+		if err != nil {
+			b.events.Emit(event.Event{Type: event.Error, Path: edits.path, Error: err.Error()})
+			return err
+		}
+		contents = append(contents, content)
+	}
+	for i, edits := range b.edited {
+		if contents[i] == nil {
+			continue
+		}
+		err := b.files.CreateFile(edits.path, contents[i])
+		if err != nil {
+			b.events.Emit(event.Event{Type: event.Error, Path: edits.path, Error: err.Error()})
+			return err
+		}
+		recorder.RecordWrite(edits.path, contents[i])
+		for _, e := range edits.events {
+			b.events.Emit(e)
+		}
+	}
+	return nil
+}
+
+func (b *Batch) addImport(action Action) error {
+	astParser, err := b.parser(action.Output)
+	if err != nil {
+		return err
+	}
+	name, added, err := astParser.AddImport(action.Import, action.Alias)
+	if err != nil {
+		return err
+	}
+	if action.Variable != "" {
+		b.data[action.Variable] = name
+	}
+	detail := action.Import
+	if action.Alias != "" && name != action.Alias {
+		detail = fmt.Sprintf("%s as %s", action.Import, name)
+	}
+	if !added {
+		b.events.Emit(event.Event{Type: event.Satisfied, Path: action.Output, Action: action.Type, Detail: detail})
+		return nil
+	}
+	b.queue(event.Event{Type: event.ASTEdit, Path: action.Output, Action: action.Type, Detail: detail})
+	return nil
+}
+
+// addStatement inserts the snippet of add_dependency, add_route or
+// add_statement at the target of action, unless the target already has it.
+func (b *Batch) addStatement(action Action, snippet string) error {
+	astParser, err := b.parser(action.Output)
+	if err != nil {
+		return err
+	}
+	code, err := b.render(snippet)
+	if err != nil {
+		return err
+	}
+	added, err := astParser.AddStatements(action.Target, []string{code})
+	if err != nil {
+		return err
+	}
+	if !added {
+		b.events.Emit(event.Event{Type: event.Satisfied, Path: action.Output, Action: action.Type, Detail: code})
+		return nil
+	}
+	b.queue(event.Event{Type: event.ASTEdit, Path: action.Output, Action: action.Type, Detail: code})
+	return nil
+}
+
+func (b *Batch) addRouteGroup(action Action) error {
+	astParser, err := b.parser(action.Output)
+	if err != nil {
+		return err
+	}
+	group := action.Group
+	group.Middlewares = []string{}
+	for _, middleware := range action.Group.Middlewares {
+		middleware, err := b.render(middleware)
+		if err != nil {
+			return err
+		}
+		group.Middlewares = append(group.Middlewares, middleware)
+	}
+	// the group may already exist under another name
+	name, groupAdded, err := astParser.AddRouteGroup(action.Target, group)
+	if err != nil {
+		return err
+	}
+	routes := []string{}
+	for _, route := range action.Routes {
+		route, err := b.render(route)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, name+"."+route)
+	}
+	// the routes go after the last statement of the group
+	groupTarget := parser.Target{Function: action.Target.Function, Receiver: name, Anchor: parser.AnchorAfter}
+	routesAdded, err := astParser.AddStatements(groupTarget, routes)
+	if err != nil {
+		return err
+	}
+	if !groupAdded && !routesAdded {
+		b.events.Emit(event.Event{Type: event.Satisfied, Path: action.Output, Action: action.Type, Detail: action.Group.Prefix})
+		return nil
+	}
+	b.queue(event.Event{Type: event.ASTEdit, Path: action.Output, Action: action.Type, Detail: action.Group.Prefix})
+	return nil
+}
+
+func (b *Batch) addMigration(action Action) error {
+	// projects generated before the schema list have nothing to add to
+	if !b.files.IsPathExists(action.Output) {
+		b.events.Emit(event.Event{
+			Type:   event.FileSkipped,
+			Path:   action.Output,
+			Reason: fmt.Sprintf("no schema list, migrate %s yourself", action.Schema),
+		})
+		return nil
+	}
+	astParser, err := b.parser(action.Output)
+	if err != nil {
+		return err
+	}
+	schema, err := b.render(action.Schema)
+	if err != nil {
+		return err
+	}
+	schema, added, err := astParser.AddMigration(action.Import, action.Alias, schema)
+	if err != nil {
+		return err
+	}
+	if !added {
+		b.events.Emit(event.Event{Type: event.Satisfied, Path: action.Output, Action: action.Type, Detail: schema})
+		return nil
+	}
+	b.queue(event.Event{Type: event.ASTEdit, Path: action.Output, Action: action.Type, Detail: schema})
+	return nil
+}
+
+func (b *Batch) addConfig(action Action) error {
+	astParser, err := b.parser(action.Output)
+	if err != nil {
+		return err
+	}
+	keys, err := astParser.AddConfig(action.Section)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		b.events.Emit(event.Event{Type: event.Satisfied, Path: action.Output, Action: action.Type, Detail: action.Section.Name})
+		return nil
+	}
+	b.queue(event.Event{Type: event.ASTEdit, Path: action.Output, Action: action.Type, Detail: strings.Join(keys, ", ")})
+
+	if action.Env == "" {
+		return nil
+	}
+	edits, err := b.text(action.Env)
+	if err != nil {
+		return err
+	}
+	added := []string{}
+	section := "\n# " + action.Section.Name + "\n"
+	for _, setting := range action.Section.Settings {
+		key := action.Section.EnvKey(setting)
+		if hasEnvKey(edits.text, key) {
+			continue
+		}
+		if setting.Description != "" {
+			section += "# " + setting.Description + "\n"
+		}
+		section += key + "=" + setting.Default + "\n"
+		added = append(added, key)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if len(edits.text) > 0 && !bytes.HasSuffix(edits.text, []byte("\n")) {
+		edits.text = append(edits.text, '\n')
+	}
+	edits.text = append(edits.text, section...)
+	b.queue(event.Event{Type: event.ASTEdit, Path: action.Env, Action: action.Type, Detail: strings.Join(added, ", ")})
+	return nil
+}
+
+// hasEnvKey reports whether the environment file content sets key.
+func hasEnvKey(content []byte, key string) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), key+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// render renders a snippet with the template data.
+func (b *Batch) render(snippet string) (string, error) {
+	content, err := b.files.ParseTemplate([]byte(snippet), b.data)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// parser returns the parser of the edits of path, parsing the file on its
+// first edit.
+func (b *Batch) parser(path string) (parser.ASTParser, error) {
+	for _, edits := range b.edited {
+		if edits.path == path && edits.parser != nil {
+			return edits.parser, nil
+		}
+	}
+	astParser, err := parser.NewASTParser(b.files, path)
+	if err != nil {
+		return nil, err
+	}
+	b.edited = append(b.edited, &fileEdits{path: path, parser: astParser})
+	return astParser, nil
+}
+
+// text returns the edits of path as text, reading the file on its first
+// edit.
+func (b *Batch) text(path string) (*fileEdits, error) {
+	for _, edits := range b.edited {
+		if edits.path == path && edits.parser == nil {
+			return edits, nil
+		}
+	}
+	content, err := b.files.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	edits := &fileEdits{path: path, text: content}
+	b.edited = append(b.edited, edits)
+	return edits, nil
+}
+
+// queue records the event of an edit, to emit once its file is written.
+func (b *Batch) queue(e event.Event) {
+	for _, edits := range b.edited {
+		if edits.path == e.Path {
+			edits.events = append(edits.events, e)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package edit
+
+import (
+	"testing"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/parser"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+const router = `package main
+
+import (
+	"demo/internal/shared/db"
+)
+
+func Routes(router *gin.Engine, database db.Database) {
+	router.GET("/health", health)
+}
+`
+
+// events collects the events of a batch.
+type events []event.Event
+
+func (e *events) Emit(ev event.Event) { *e = append(*e, ev) }
+
+// written collects the contents a batch writes.
+type written map[string]string
+
+func (w written) RecordWrite(path string, content []byte) { w[path] = string(content) }
+
+func newBatch(t *testing.T) (*Batch, file.File, *events) {
+	t.Helper()
+	files := file.New(file.NewMemFS())
+	if err := files.CreateFile(parser.RouterFile, []byte(router)); err != nil {
+		t.Fatalf("Failed to write router: %v", err)
+	}
+	emitted := &events{}
+	return NewBatch(files, emitted, map[string]string{}), files, emitted
+}
+
+func TestBatch(t *testing.T) {
+	batch, files, emitted := newBatch(t)
+	actions := []Action{
+		{Type: "add_import", Output: parser.RouterFile, Import: "demo/internal/order/application", Alias: "orderApp", Variable: "app"},
+		{Type: "add_dependency", Output: parser.RouterFile, Dependency: "orderService := {{ .app }}.NewOrderService()", Target: parser.DependencyTarget},
+		{Type: "add_route", Output: parser.RouterFile, Route: `router.GET("/orders", orderService.List)`, Target: parser.RouteTarget},
+	}
+	for _, action := range actions {
+		if err := batch.Run(action); err != nil {
+			t.Fatalf("Failed to run %s: %v", action.Type, err)
+		}
+	}
+	// nothing is written or reported before Write
+	content, _ := files.ReadFile(parser.RouterFile)
+	assert.Equal(t, string(content), router)
+	assert.Equal(t, len(*emitted), 0)
+
+	recorded := written{}
+	if err := batch.Write(recorded); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	content, _ = files.ReadFile(parser.RouterFile)
+	assert.Equal(t, recorded[parser.RouterFile], string(content))
+	assert.Equal(t, string(content), `package main
+
+import (
+	orderApp "demo/internal/order/application"
+	"demo/internal/shared/db"
+)
+
+func Routes(router *gin.Engine, database db.Database) {
+	orderService := orderApp.NewOrderService()
+	router.GET("/health", health)
+	router.GET("/orders", orderService.List)
+}
+`)
+	assert.Equal(t, len(*emitted), 3)
+	assert.Equal(t, (*emitted)[1], event.Event{
+		Type:   event.ASTEdit,
+		Path:   parser.RouterFile,
+		Action: "add_dependency",
+		Detail: "orderService := orderApp.NewOrderService()",
+	})
+}
+
+func TestBatch_Repeated(t *testing.T) {
+	_, files, _ := newBatch(t)
+	actions := []Action{
+		{Type: "add_import", Output: parser.RouterFile, Import: "demo/internal/order/application", Alias: "orderApp"},
+		{Type: "add_dependency", Output: parser.RouterFile, Dependency: "orderService := orderApp.NewOrderService()", Target: parser.DependencyTarget},
+		{Type: "add_route", Output: parser.RouterFile, Route: `router.GET("/orders", orderService.List)`, Target: parser.RouteTarget},
+		{
+			Type:   "add_route_group",
+			Output: parser.RouterFile,
+			Target: parser.RouteTarget,
+			Group:  parser.RouteGroup{Name: "admin", Prefix: "/admin"},
+			Routes: []string{`GET("/orders", orderService.Admin)`},
+		},
+	}
+	for run := 0; run < 2; run++ {
+		emitted := &events{}
+		batch := NewBatch(files, emitted, map[string]string{})
+		for _, action := range actions {
+			if err := batch.Run(action); err != nil {
+				t.Fatalf("Failed to run %s: %v", action.Type, err)
+			}
+		}
+		recorded := written{}
+		if err := batch.Write(recorded); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		// the second run finds everything in place and writes nothing
+		types := []event.Type{}
+		for _, ev := range *emitted {
+			types = append(types, ev.Type)
+		}
+		if run == 0 {
+			assert.Equal(t, types, []event.Type{event.ASTEdit, event.ASTEdit, event.ASTEdit, event.ASTEdit})
+			continue
+		}
+		assert.Equal(t, types, []event.Type{event.Satisfied, event.Satisfied, event.Satisfied, event.Satisfied})
+		assert.Equal(t, len(recorded), 0)
+	}
+}
+
+func TestBatch_Invalid(t *testing.T) {
+	batch, files, emitted := newBatch(t)
+	for _, dependency := range []string{"database := db.New()", "orderService := NewOrderService()"} {
+		action := Action{Type: "add_dependency", Output: parser.RouterFile, Dependency: dependency, Target: parser.DependencyTarget}
+		if err := batch.Run(action); err != nil {
+			t.Fatalf("Failed to run add_dependency: %v", err)
+		}
+	}
+
+	// database is a parameter of Routes already
+	if err := batch.Write(written{}); err == nil {
+		t.Fatal("Expected the redeclared variable to be reported")
+	}
+	content, _ := files.ReadFile(parser.RouterFile)
+	assert.Equal(t, string(content), router)
+	assert.Equal(t, len(*emitted), 1)
+	assert.Equal(t, (*emitted)[0].Type, event.Error)
+
+	if err := batch.Run(Action{Type: "add_nothing", Output: parser.RouterFile}); err == nil {
+		t.Fatal("Expected an unknown action type to fail")
+	}
+}
+
+func TestBatch_MissingMigrations(t *testing.T) {
+	batch, _, emitted := newBatch(t)
+	action := Action{Type: "add_migration", Output: parser.MigrationsFile, Import: "demo/internal/order/infrastructure", Schema: "OrderSchema"}
+	if err := batch.Run(action); err != nil {
+		t.Fatalf("Failed to run add_migration: %v", err)
+	}
+	assert.Equal(t, (*emitted)[0].Type, event.FileSkipped)
+
+	// the files of the other actions are part of every project
+	action = Action{Type: "add_route", Output: "cmd/server/missing.go", Route: "router.GET(\"/\", home)", Target: parser.RouteTarget}
+	if err := batch.Run(action); err == nil {
+		t.Fatal("Expected a missing file to fail")
+	}
+}
@@ -0,0 +1,184 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+type Type string
+
+const (
+	FileCreated Type = "file_created"
+	FileSkipped Type = "file_skipped"
+	ASTEdit     Type = "ast_edit"
+	HookStarted Type = "hook_started"
+	HookFailed  Type = "hook_failed"
+	Satisfied   Type = "satisfied"
+	Error       Type = "error"
+	Summary     Type = "summary"
+)
+
+// Event is a single step of a generation. Only the fields that apply to
+// the event type are set.
+type Event struct {
+	Type     Type           `json:"type"`
+	Path     string         `json:"path,omitempty"`
+	Template string         `json:"template,omitempty"`
+	Action   string         `json:"action,omitempty"`
+	Detail   string         `json:"detail,omitempty"`
+	Hook     string         `json:"hook,omitempty"`
+	Reason   string         `json:"reason,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Summary  *SummaryDetail `json:"summary,omitempty"`
+}
+
+// SummaryDetail counts what a command did. It is attached to the last
+// event of every command.
+type SummaryDetail struct {
+	Command      string `json:"command"`
+	Success      bool   `json:"success"`
+	FilesCreated int    `json:"filesCreated"`
+	FilesSkipped int    `json:"filesSkipped"`
+	ASTEdits     int    `json:"astEdits"`
+	HooksRun     int    `json:"hooksRun"`
+	HooksFailed  int    `json:"hooksFailed"`
+	Satisfied    int    `json:"satisfied"`
+	Errors       int    `json:"errors"`
+}
+
+type Emitter interface {
+	Emit(event Event)
+}
+
+// Discard is an Emitter that drops every event.
+var Discard Emitter = discard{}
+
+type discard struct{}
+
+func (discard) Emit(Event) {}
+
+type jsonEmitter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONEmitter returns an Emitter that writes one JSON object per line.
+func NewJSONEmitter(w io.Writer) Emitter {
+	return &jsonEmitter{encoder: json.NewEncoder(w)}
+}
+
+func (e *jsonEmitter) Emit(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.encoder.Encode(event)
+}
+
+type textEmitter struct {
+	logger *log.Logger
+}
+
+// NewTextEmitter returns an Emitter that writes human-readable log lines.
+func NewTextEmitter(w io.Writer) Emitter {
+	return &textEmitter{logger: log.New(w, "", log.LstdFlags)}
+}
+
+func (e *textEmitter) Emit(event Event) {
+	e.logger.Print(Text(event))
+}
+
+// Text renders an event as a human-readable line.
+func Text(event Event) string {
+	switch event.Type {
+	case FileCreated:
+		return "Created: " + event.Path
+	case FileSkipped:
+		return fmt.Sprintf("Skipped: %s (%s)", event.Path, event.Reason)
+	case ASTEdit:
+		return fmt.Sprintf("Edited: %s (%s %s)", event.Path, event.Action, event.Detail)
+	case HookStarted:
+		return "Running: " + event.Hook
+	case HookFailed:
+		return fmt.Sprintf("Failed: %s: %s", event.Hook, event.Error)
+	case Satisfied:
+		return fmt.Sprintf("Satisfied: %s %s", event.Action, event.Detail)
+	case Error:
+		if event.Template != "" {
+			return fmt.Sprintf("Error: %s: %s", event.Template, event.Error)
+		}
+		if event.Path != "" {
+			return fmt.Sprintf("Error: %s: %s", event.Path, event.Error)
+		}
+		return "Error: " + event.Error
+	case Summary:
+		summary := event.Summary
+		status := "Done"
+		if !summary.Success {
+			status = "Completed with errors"
+		}
+		parts := []string{
+			fmt.Sprintf("%d file(s) created", summary.FilesCreated),
+			fmt.Sprintf("%d skipped", summary.FilesSkipped),
+			fmt.Sprintf("%d AST edit(s)", summary.ASTEdits),
+			fmt.Sprintf("%d hook(s) run", summary.HooksRun),
+		}
+		if summary.Satisfied > 0 {
+			parts = append(parts, fmt.Sprintf("%d satisfied", summary.Satisfied))
+		}
+		if summary.Errors > 0 {
+			parts = append(parts, fmt.Sprintf("%d error(s)", summary.Errors))
+		}
+		return fmt.Sprintf("%s: %s", status, strings.Join(parts, ", "))
+	}
+	return string(event.Type)
+}
+
+// Recorder passes events on to an Emitter and counts them, so a summary
+// can be emitted when the command finishes.
+type Recorder struct {
+	mu      sync.Mutex
+	emitter Emitter
+	summary SummaryDetail
+}
+
+func NewRecorder(emitter Emitter) *Recorder {
+	return &Recorder{emitter: emitter}
+}
+
+func (r *Recorder) Emit(event Event) {
+	r.mu.Lock()
+	switch event.Type {
+	case FileCreated:
+		r.summary.FilesCreated++
+	case FileSkipped:
+		r.summary.FilesSkipped++
+	case ASTEdit:
+		r.summary.ASTEdits++
+	case HookStarted:
+		r.summary.HooksRun++
+	case HookFailed:
+		r.summary.HooksFailed++
+	case Satisfied:
+		r.summary.Satisfied++
+	case Error:
+		r.summary.Errors++
+	}
+	r.mu.Unlock()
+	r.emitter.Emit(event)
+}
+
+// Summary emits the summary event for command. The command succeeded when
+// no errors or failed hooks were recorded.
+func (r *Recorder) Summary(command string) SummaryDetail {
+	r.mu.Lock()
+	summary := r.summary
+	r.mu.Unlock()
+
+	summary.Command = command
+	summary.Success = summary.Errors == 0 && summary.HooksFailed == 0
+	r.emitter.Emit(Event{Type: Summary, Summary: &summary})
+	return summary
+}
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestJSONEmitter(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(NewJSONEmitter(&buf))
+
+	recorder.Emit(Event{Type: FileCreated, Path: "internal/order/domain/order.entity.go", Template: "entity.go.tmpl"})
+	recorder.Emit(Event{Type: ASTEdit, Path: "cmd/server/router.go", Action: "add_import", Detail: "demo/internal/order/application"})
+	recorder.Emit(Event{Type: HookStarted, Hook: "go mod tidy"})
+	recorder.Emit(Event{Type: HookFailed, Hook: "go mod tidy", Error: "exit status 1"})
+	summary := recorder.Summary("context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 5)
+	assert.Equal(t, lines[0], `{"type":"file_created","path":"internal/order/domain/order.entity.go","template":"entity.go.tmpl"}`)
+
+	var last Event
+	if err := json.Unmarshal([]byte(lines[4]), &last); err != nil {
+		t.Fatalf("Error decoding summary: %v", err)
+	}
+	assert.Equal(t, last.Type, Summary)
+	assert.Equal(t, *last.Summary, summary)
+	assert.Equal(t, summary, SummaryDetail{
+		Command:      "context",
+		Success:      false,
+		FilesCreated: 1,
+		ASTEdits:     1,
+		HooksRun:     1,
+		HooksFailed:  1,
+	})
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		input    Event
+		expected string
+	}{
+		{
+			input:    Event{Type: FileCreated, Path: "go.mod"},
+			expected: "Created: go.mod",
+		},
+		{
+			input:    Event{Type: FileSkipped, Path: "internal/order", Reason: "context already exists"},
+			expected: "Skipped: internal/order (context already exists)",
+		},
+		{
+			input:    Event{Type: Error, Template: "schema.go.tmpl", Error: "schema.go.tmpl:6:25: expected '{', found '('"},
+			expected: "Error: schema.go.tmpl: schema.go.tmpl:6:25: expected '{', found '('",
+		},
+		{
+			input:    Event{Type: Summary, Summary: &SummaryDetail{Success: true, FilesCreated: 6, HooksRun: 1}},
+			expected: "Done: 6 file(s) created, 0 skipped, 0 AST edit(s), 1 hook(s) run",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, Text(tt.input), tt.expected)
+	}
+}
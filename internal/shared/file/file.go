@@ -2,47 +2,103 @@ package file
 
 import (
 	"bytes"
-	"os"
-	"path/filepath"
+	"errors"
+	"io/fs"
 	"strings"
 	"text/template"
 
+	"github.com/IrwantoCia/gomakase/internal/shared/naming"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+// File reads and writes files relative to its root directory.
 type File interface {
 	CreateFile(path string, content []byte) error
+	ReadFile(path string) ([]byte, error)
 	IsPathExists(path string) bool
+	ListFiles(dir string) ([]string, error)
+	ListDirs(dir string) ([]string, error)
 	ParseFilePath(path string, data map[string]string) (string, error)
 	ParseTemplate(content []byte, data map[string]string) ([]byte, error)
 }
 
 type file struct {
+	fsys FS
 }
 
+// New returns a File that reads and writes through fsys.
+func New(fsys FS) File {
+	return &file{fsys: fsys}
+}
+
+// NewFile returns a File rooted at the working directory.
 func NewFile() File {
-	return &file{}
+	return New(DirFS(""))
+}
+
+// NewFileAt returns a File rooted at the given directory, usually the
+// project root.
+func NewFileAt(root string) File {
+	return New(DirFS(root))
 }
 
 func (f *file) CreateFile(path string, content []byte) error {
-	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		os.MkdirAll(dir, 0755)
+	return f.fsys.WriteFile(path, content)
+}
+
+func (f *file) ReadFile(path string) ([]byte, error) {
+	return f.fsys.ReadFile(path)
+}
+
+// ListFiles returns the names of the files directly in dir, without the
+// directories, sorted. It fails when the filesystem cannot list
+// directories.
+func (f *file) ListFiles(dir string) ([]string, error) {
+	return f.list(dir, false)
+}
+
+// ListDirs returns the names of the directories directly in dir, sorted.
+// It fails when the filesystem cannot list directories.
+func (f *file) ListDirs(dir string) ([]string, error) {
+	return f.list(dir, true)
+}
+
+func (f *file) list(dir string, dirs bool) ([]string, error) {
+	dirFS, ok := f.fsys.(ReadDirFS)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	entries, err := dirFS.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() == dirs {
+			names = append(names, entry.Name())
+		}
 	}
-	return os.WriteFile(path, content, 0644)
+	return names, nil
 }
 
 func (f *file) IsPathExists(path string) bool {
-	_, err := os.Stat(path)
-	return !os.IsNotExist(err)
+	_, err := f.fsys.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// funcs are the functions available in templates and output paths.
+var funcs = template.FuncMap{
+	"lower":  strings.ToLower,
+	"title":  cases.Title(language.English).String,
+	"pascal": naming.Pascal,
+	"camel":  naming.Camel,
+	"kebab":  naming.Kebab,
+	"snake":  naming.Snake,
 }
 
 func (f *file) ParseFilePath(path string, data map[string]string) (string, error) {
-	tmpl, err := template.New("output").Funcs(template.FuncMap{
-		"lower": strings.ToLower,
-		"title": cases.Title(language.English).String,
-	}).Parse(path)
+	tmpl, err := template.New("output").Funcs(funcs).Parse(path)
 	if err != nil {
 		return path, err
 	}
@@ -54,10 +110,7 @@ func (f *file) ParseFilePath(path string, data map[string]string) (string, error
 }
 
 func (f *file) ParseTemplate(content []byte, data map[string]string) ([]byte, error) {
-	tmpl, err := template.New("template").Funcs(template.FuncMap{
-		"lower": strings.ToLower,
-		"title": cases.Title(language.English).String,
-	}).Parse(string(content))
+	tmpl, err := template.New("template").Funcs(funcs).Parse(string(content))
 	if err != nil {
 		return []byte{}, err
 	}
@@ -3,6 +3,7 @@ package file
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"gopkg.in/go-playground/assert.v1"
@@ -71,3 +72,21 @@ func TestFile_IsPathExists(t *testing.T) {
 	exists := file.IsPathExists(input)
 	assert.Equal(t, exists, expected)
 }
+
+func TestFile_NewFileAt(t *testing.T) {
+	root := t.TempDir()
+
+	file := NewFileAt(root)
+	err := file.CreateFile("internal/order/domain/order.entity.go", []byte("package domain\n"))
+	if err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+
+	assert.Equal(t, file.IsPathExists("internal/order/domain/order.entity.go"), true)
+
+	content, err := os.ReadFile(filepath.Join(root, "internal", "order", "domain", "order.entity.go"))
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	assert.Equal(t, string(content), "package domain\n")
+}
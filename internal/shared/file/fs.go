@@ -0,0 +1,244 @@
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is a writable filesystem rooted at the project directory. Names are
+// slash-separated and relative to the root.
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// ReadDirFS is an FS that also lists directories, which the commands that
+// read a whole package need. DirFS and MemFS are ReadDirFS.
+type ReadDirFS interface {
+	FS
+	ReadDir(name string) ([]fs.DirEntry, error)
+}
+
+type dirFS struct {
+	dir string
+}
+
+// DirFS returns an FS for the directory dir on disk. An empty dir is the
+// working directory.
+func DirFS(dir string) FS {
+	return &dirFS{dir: dir}
+}
+
+// errNotLocal is the error of a name that is absolute or leaves the root.
+var errNotLocal = errors.New("path is outside the root")
+
+// cleanName returns name cleaned and slash-separated. Names that are
+// absolute or that leave the root, such as ../go.mod, are rejected the same
+// way by every FS, so a template cannot write outside the project.
+func cleanName(op string, name string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", &fs.PathError{Op: op, Path: name, Err: errNotLocal}
+	}
+	return path.Clean(filepath.ToSlash(name)), nil
+}
+
+// join returns the path on disk of name, cleaned.
+func (d *dirFS) join(op string, name string) (string, error) {
+	name, err := cleanName(op, name)
+	if err != nil {
+		return "", err
+	}
+	local := filepath.FromSlash(name)
+	if d.dir == "" {
+		return local, nil
+	}
+	return filepath.Join(d.dir, local), nil
+}
+
+func (d *dirFS) ReadFile(name string) ([]byte, error) {
+	path, err := d.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// WriteFile writes data to a temporary file next to name and renames it to
+// name, so an existing file is replaced whole or not at all. The mode of an
+// existing file is kept.
+func (d *dirFS) WriteFile(name string, data []byte) error {
+	path, err := d.join("write", name)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	mode := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *dirFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := d.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+func (d *dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := d.join("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(path)
+}
+
+// MemFS is an FS that keeps its files in memory. Directories exist while
+// they contain a file, so an empty MemFS has no root directory either.
+type MemFS struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+func NewMemFS() *MemFS {
+	return &MemFS{files: make(map[string][]byte)}
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	key, err := cleanName("open", name)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.files[key]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return slices.Clone(data), nil
+}
+
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	key, err := cleanName("write", name)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[key] = slices.Clone(data)
+	return nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	name, err := cleanName("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if data, ok := m.files[name]; ok {
+		return &memInfo{name: path.Base(name), size: int64(len(data))}, nil
+	}
+	for file := range m.files {
+		if name == "." || strings.HasPrefix(file, name+"/") {
+			return &memInfo{name: path.Base(name), dir: true}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir returns the files and directories directly in the directory
+// name, sorted by name.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name, err := cleanName("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	entries := map[string]fs.DirEntry{}
+	for file, data := range m.files {
+		rest, ok := strings.CutPrefix(file, prefix)
+		if !ok {
+			continue
+		}
+		if child, _, isDir := strings.Cut(rest, "/"); isDir {
+			entries[child] = fs.FileInfoToDirEntry(&memInfo{name: child, dir: true})
+		} else {
+			entries[rest] = fs.FileInfoToDirEntry(&memInfo{name: rest, size: int64(len(data))})
+		}
+	}
+	if len(entries) == 0 {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	names := slices.Sorted(maps.Keys(entries))
+	list := make([]fs.DirEntry, 0, len(names))
+	for _, name := range names {
+		list = append(list, entries[name])
+	}
+	return list, nil
+}
+
+// Paths returns the names of all files, sorted.
+func (m *MemFS) Paths() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	paths := make([]string, 0, len(m.files))
+	for name := range m.files {
+		paths = append(paths, name)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i *memInfo) Name() string { return i.name }
+func (i *memInfo) Size() int64  { return i.size }
+func (i *memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+func (i *memInfo) ModTime() time.Time { return time.Time{} }
+func (i *memInfo) IsDir() bool        { return i.dir }
+func (i *memInfo) Sys() any           { return nil }
@@ -0,0 +1,133 @@
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestMemFS(t *testing.T) {
+	memFS := NewMemFS()
+	file := New(memFS)
+	assert.Equal(t, file.IsPathExists("."), false)
+
+	err := file.CreateFile("internal/order/domain/order.entity.go", []byte("package domain\n"))
+	if err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+	err = file.CreateFile("./go.mod", []byte("module demo\n"))
+	if err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+
+	assert.Equal(t, file.IsPathExists("."), true)
+	assert.Equal(t, file.IsPathExists("internal/order"), true)
+	assert.Equal(t, file.IsPathExists("internal/ord"), false)
+	assert.Equal(t, memFS.Paths(), []string{"go.mod", "internal/order/domain/order.entity.go"})
+
+	info, err := memFS.Stat("internal/order/domain/order.entity.go")
+	if err != nil {
+		t.Fatalf("Error reading file info: %v", err)
+	}
+	assert.Equal(t, info.Name(), "order.entity.go")
+	assert.Equal(t, info.Size(), int64(len("package domain\n")))
+	assert.Equal(t, info.IsDir(), false)
+
+	content, err := file.ReadFile("go.mod")
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	assert.Equal(t, string(content), "module demo\n")
+
+	_, err = file.ReadFile("missing.go")
+	assert.Equal(t, errors.Is(err, fs.ErrNotExist), true)
+
+	names, err := file.ListFiles(".")
+	if err != nil {
+		t.Fatalf("Error listing files: %v", err)
+	}
+	assert.Equal(t, names, []string{"go.mod"})
+	names, err = file.ListDirs("internal")
+	if err != nil {
+		t.Fatalf("Error listing directories: %v", err)
+	}
+	assert.Equal(t, names, []string{"order"})
+	names, err = file.ListFiles("internal/order/domain")
+	if err != nil {
+		t.Fatalf("Error listing files: %v", err)
+	}
+	assert.Equal(t, names, []string{"order.entity.go"})
+	_, err = file.ListFiles("internal/customer")
+	assert.Equal(t, errors.Is(err, fs.ErrNotExist), true)
+}
+
+func TestDirFS_WriteFile(t *testing.T) {
+	root := t.TempDir()
+	dirFS := DirFS(root)
+
+	if err := os.WriteFile(filepath.Join(root, "run.sh"), []byte("echo old\n"), 0755); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	if err := dirFS.WriteFile("run.sh", []byte("echo new\n")); err != nil {
+		t.Fatalf("Error replacing file: %v", err)
+	}
+
+	content, err := dirFS.ReadFile("run.sh")
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	assert.Equal(t, string(content), "echo new\n")
+	info, err := dirFS.Stat("run.sh")
+	if err != nil {
+		t.Fatalf("Error reading file info: %v", err)
+	}
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0755))
+
+	// no temporary file is left behind
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("Error reading directory: %v", err)
+	}
+	assert.Equal(t, len(entries), 1)
+}
+
+func TestFS_OutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	filesystems := []struct {
+		name string
+		fsys ReadDirFS
+	}{
+		{"DirFS", DirFS(filepath.Join(root, "project")).(ReadDirFS)},
+		{"MemFS", NewMemFS()},
+	}
+	for _, tt := range filesystems {
+		// names are cleaned before they are looked up
+		if err := tt.fsys.WriteFile("internal/../go.mod", []byte("module demo\n")); err != nil {
+			t.Fatalf("%s: error writing file: %v", tt.name, err)
+		}
+		if _, err := tt.fsys.ReadFile("./go.mod"); err != nil {
+			t.Fatalf("%s: expected go.mod in the root: %v", tt.name, err)
+		}
+
+		for _, name := range []string{"../escaped.sh", "internal/../../escaped.sh", "/etc/passwd", filepath.Join(root, "escaped.sh")} {
+			err := tt.fsys.WriteFile(name, []byte("echo\n"))
+			if !errors.Is(err, errNotLocal) || !strings.Contains(err.Error(), name) {
+				t.Fatalf("%s: expected an error naming %s, got %v", tt.name, name, err)
+			}
+			_, err = tt.fsys.ReadFile(name)
+			assert.Equal(t, errors.Is(err, errNotLocal), true)
+			_, err = tt.fsys.Stat(name)
+			assert.Equal(t, errors.Is(err, errNotLocal), true)
+			_, err = tt.fsys.ReadDir(name)
+			assert.Equal(t, errors.Is(err, errNotLocal), true)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "escaped.sh")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected no file outside the root: %v", err)
+	}
+}
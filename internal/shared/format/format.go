@@ -0,0 +1,130 @@
+package format
+
+import (
+	"bytes"
+	"go/ast"
+	gofmt "go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+// IsGoFile reports whether the output path is a Go source file that should
+// be formatted before it is written.
+func IsGoFile(path string) bool {
+	return strings.HasSuffix(path, ".go")
+}
+
+// Source formats rendered Go code the same way gofmt does, sorts and groups
+// its imports the same way goimports does and removes the imports it does
+// not use. Missing imports are not added: goimports resolves them from the
+// working directory and the module cache, so the result would depend on
+// the machine gomakase runs on, and templates import what they use.
+//
+// The name is used as the file name in error positions, so callers should
+// pass the template the code was rendered from. A syntax error is reported
+// as "<name>:<line>:<column>: <message>".
+func Source(name string, src []byte) ([]byte, error) {
+	formatted, err := imports.Process(name, src, &imports.Options{
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+		FormatOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pruneImports(name, formatted)
+}
+
+// versionSuffix matches the major version element of an import path, as
+// in github.com/golang-jwt/jwt/v5.
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// ImportNames returns the names an import may be referred to by: its
+// alias or, without one, the last element of its path and, before a major
+// version, the element before it, since jwt/v5 is jwt but core/v1 is v1.
+func ImportNames(importSpec *ast.ImportSpec) []string {
+	if importSpec.Name != nil {
+		return []string{importSpec.Name.Name}
+	}
+	importPath, err := strconv.Unquote(importSpec.Path.Value)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, name := range []string{path.Base(importPath), path.Base(path.Dir(importPath))} {
+		if token.IsIdentifier(name) {
+			names = append(names, name)
+		}
+		if !versionSuffix.MatchString(name) {
+			break
+		}
+	}
+	return names
+}
+
+// pruneImports removes the imports of src none of whose names is used.
+// The package names are told from the import paths, so when the file
+// refers to a package no import path tells the name of, nothing is
+// removed, since that package may be any of the imports.
+func pruneImports(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// the packages are the selector bases the file does not declare
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if selExpr, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := selExpr.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	known := map[string]bool{}
+	for _, importSpec := range file.Imports {
+		for _, name := range ImportNames(importSpec) {
+			known[name] = true
+		}
+	}
+	for name := range used {
+		if !known[name] {
+			return src, nil
+		}
+	}
+
+	removed := false
+	for _, importSpec := range slices.Clone(file.Imports) {
+		names := ImportNames(importSpec)
+		if len(names) == 0 || slices.ContainsFunc(names, func(name string) bool {
+			return name == "_" || name == "." || used[name]
+		}) {
+			continue
+		}
+		alias := ""
+		if importSpec.Name != nil {
+			alias = importSpec.Name.Name
+		}
+		importPath, _ := strconv.Unquote(importSpec.Path.Value)
+		removed = astutil.DeleteNamedImport(fset, file, alias, importPath) || removed
+	}
+	if !removed {
+		return src, nil
+	}
+	var buf bytes.Buffer
+	if err := gofmt.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
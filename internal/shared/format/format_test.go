@@ -0,0 +1,91 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestSource(t *testing.T) {
+	input := `package main
+import (
+    "strings"
+	"fmt"
+)
+func main() {
+  fmt.Println(strings.ToUpper("ok"))
+}
+`
+	expected := `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	fmt.Println(strings.ToUpper("ok"))
+}
+`
+
+	content, err := Source("main.go.tmpl", []byte(input))
+	if err != nil {
+		t.Fatalf("Error formatting source: %v", err)
+	}
+	assert.Equal(t, string(content), expected)
+}
+
+func TestSource_Imports(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// unused imports are removed
+		{
+			input:    "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {\n\tfmt.Println(\"ok\")\n}\n",
+			expected: "package main\n\nimport (\n\t\"fmt\"\n)\n\nfunc main() {\n\tfmt.Println(\"ok\")\n}\n",
+		},
+		// the package of core/v1 may be v1
+		{
+			input:    "package main\n\nimport (\n\t\"os\"\n\n\t\"k8s.io/api/core/v1\"\n)\n\nvar pod v1.Pod\n",
+			expected: "package main\n\nimport (\n\t\"k8s.io/api/core/v1\"\n)\n\nvar pod v1.Pod\n",
+		},
+		// no import path tells the name of baz, which may be any import
+		{
+			input:    "package main\n\nimport (\n\t\"os\"\n\n\t\"example.com/go-baz\"\n)\n\nvar x = baz.X\n",
+			expected: "package main\n\nimport (\n\t\"os\"\n\n\t\"example.com/go-baz\"\n)\n\nvar x = baz.X\n",
+		},
+		// missing imports are not added, whatever packages the machine has
+		{
+			input:    "package main\n\nfunc main() {\n\tfmt.Println(\"ok\")\n}\n",
+			expected: "package main\n\nfunc main() {\n\tfmt.Println(\"ok\")\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		content, err := Source("main.go.tmpl", []byte(tt.input))
+		if err != nil {
+			t.Fatalf("Error formatting source: %v", err)
+		}
+		assert.Equal(t, string(content), tt.expected)
+	}
+}
+
+func TestSource_SyntaxError(t *testing.T) {
+	input := "package main\n\nfunc main() {\n\tif {\n}\n"
+
+	_, err := Source("handler.go.tmpl", []byte(input))
+	if err == nil {
+		t.Fatalf("Expected a syntax error")
+	}
+	if !strings.HasPrefix(err.Error(), "handler.go.tmpl:4:") {
+		t.Fatalf("Expected error position in handler.go.tmpl, got: %v", err)
+	}
+}
+
+func TestIsGoFile(t *testing.T) {
+	assert.Equal(t, IsGoFile("cmd/server/main.go"), true)
+	assert.Equal(t, IsGoFile("internal/shared/logger/logger_test.go"), true)
+	assert.Equal(t, IsGoFile("go.mod"), false)
+	assert.Equal(t, IsGoFile("web/static/js/src/main.js"), false)
+}
@@ -0,0 +1,182 @@
+package naming
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// initialisms are written in upper case inside Go identifiers, as golint
+// expects (OrderID, not OrderId).
+var initialisms = map[string]bool{
+	"api":  true,
+	"html": true,
+	"http": true,
+	"id":   true,
+	"json": true,
+	"sql":  true,
+	"uid":  true,
+	"url":  true,
+	"uuid": true,
+}
+
+// Words splits name into lowercase words at underscores, dashes, dots,
+// spaces and camel case boundaries, so "order_item", "order-item" and
+// "OrderItem" all give [order item].
+func Words(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == '.' || unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// "orderItem" breaks before I, "HTTPServer" breaks before S
+			if !unicode.IsUpper(prev) || nextLower {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// Pascal returns name as an exported Go identifier, e.g. OrderItem.
+func Pascal(name string) string {
+	var b strings.Builder
+	for _, word := range Words(name) {
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+// Camel returns name as an unexported Go identifier, e.g. orderItem.
+func Camel(name string) string {
+	var b strings.Builder
+	for i, word := range Words(name) {
+		if i == 0 {
+			b.WriteString(word)
+			continue
+		}
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+// Package returns name as a Go package name, e.g. orderitem.
+func Package(name string) string {
+	return strings.Join(Words(name), "")
+}
+
+// Kebab returns name in lowercase words joined by dashes, e.g. order-item,
+// the form used in URL paths.
+func Kebab(name string) string {
+	return strings.Join(Words(name), "-")
+}
+
+// Snake returns name in lowercase words joined by underscores, e.g.
+// order_item.
+func Snake(name string) string {
+	return strings.Join(Words(name), "_")
+}
+
+func capitalize(word string) string {
+	if initialisms[word] {
+		return strings.ToUpper(word)
+	}
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// Context is a parsed context name. A context may be nested under parent
+// directories by separating them with slashes, e.g. billing/invoice.
+type Context struct {
+	// Name is the canonical name recorded in gen.yaml, e.g. billing/order_item.
+	Name string
+	// Path is the directory of the context under internal, e.g. billing/orderitem.
+	Path string
+	// Package is the Go package name of the context, e.g. orderitem.
+	Package string
+	// Type is the exported type name of the context, e.g. OrderItem.
+	Type string
+	// Var is the unexported identifier of the context, e.g. orderItem.
+	Var string
+	// PathVar is the unexported identifier of the context and its parents,
+	// e.g. billingOrderItem. Code outside the context uses it, so nested
+	// contexts that share a name do not collide.
+	PathVar string
+	// URL is the URL path of the context, e.g. billing/order-item.
+	URL string
+}
+
+// ParseContext parses a context name such as "order_item", "OrderItem" or
+// "billing/invoice".
+func ParseContext(name string) (Context, error) {
+	segments := strings.Split(strings.Trim(name, "/"), "/")
+	names := []string{}
+	paths := []string{}
+	urls := []string{}
+	for _, segment := range segments {
+		words := Words(segment)
+		if len(words) == 0 {
+			return Context{}, fmt.Errorf("invalid context name %q: empty path element", name)
+		}
+		for _, word := range words {
+			for _, r := range word {
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					return Context{}, fmt.Errorf("invalid context name %q: unexpected character %q", name, r)
+				}
+			}
+		}
+		if unicode.IsDigit([]rune(words[0])[0]) {
+			return Context{}, fmt.Errorf("invalid context name %q: %q must start with a letter", name, segment)
+		}
+		names = append(names, Snake(segment))
+		paths = append(paths, Package(segment))
+		urls = append(urls, Kebab(segment))
+	}
+
+	last := segments[len(segments)-1]
+	context := Context{
+		Name:    strings.Join(names, "/"),
+		Path:    strings.Join(paths, "/"),
+		Package: Package(last),
+		Type:    Pascal(last),
+		Var:     Camel(last),
+		PathVar: Camel(strings.Join(names, "_")),
+		URL:     strings.Join(urls, "/"),
+	}
+	if token.IsKeyword(context.Package) {
+		return Context{}, fmt.Errorf("invalid context name %q: %q is a Go keyword", name, context.Package)
+	}
+	return context, nil
+}
+
+// Data returns the template variables of the context.
+func (c Context) Data() map[string]string {
+	return map[string]string{
+		"ContextName":    c.Name,
+		"ContextPath":    c.Path,
+		"ContextPackage": c.Package,
+		"ContextType":    c.Type,
+		"ContextVar":     c.Var,
+		"ContextPathVar": c.PathVar,
+		"ContextURL":     c.URL,
+	}
+}
@@ -0,0 +1,72 @@
+package naming
+
+import (
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"user":       {"user"},
+		"order_item": {"order", "item"},
+		"order-item": {"order", "item"},
+		"OrderItem":  {"order", "item"},
+		"orderItem":  {"order", "item"},
+		"HTTPServer": {"http", "server"},
+		"userID":     {"user", "id"},
+		"v2 api":     {"v2", "api"},
+		"__":         {},
+	}
+	for name, want := range tests {
+		assert.Equal(t, Words(name), want)
+	}
+}
+
+func TestCases(t *testing.T) {
+	assert.Equal(t, Pascal("order_item"), "OrderItem")
+	assert.Equal(t, Pascal("user_id"), "UserID")
+	assert.Equal(t, Camel("order_item"), "orderItem")
+	assert.Equal(t, Camel("api_key"), "apiKey")
+	assert.Equal(t, Package("order_item"), "orderitem")
+	assert.Equal(t, Kebab("OrderItem"), "order-item")
+	assert.Equal(t, Snake("OrderItem"), "order_item")
+}
+
+func TestParseContext(t *testing.T) {
+	context, err := ParseContext("billing/OrderItem")
+	if err != nil {
+		t.Fatalf("Failed to parse context: %v", err)
+	}
+	assert.Equal(t, context, Context{
+		Name:    "billing/order_item",
+		Path:    "billing/orderitem",
+		Package: "orderitem",
+		Type:    "OrderItem",
+		Var:     "orderItem",
+		PathVar: "billingOrderItem",
+		URL:     "billing/order-item",
+	})
+
+	context, err = ParseContext("user")
+	if err != nil {
+		t.Fatalf("Failed to parse context: %v", err)
+	}
+	assert.Equal(t, context.Data(), map[string]string{
+		"ContextName":    "user",
+		"ContextPath":    "user",
+		"ContextPackage": "user",
+		"ContextType":    "User",
+		"ContextVar":     "user",
+		"ContextPathVar": "user",
+		"ContextURL":     "user",
+	})
+}
+
+func TestParseContext_Invalid(t *testing.T) {
+	for _, name := range []string{"", "billing//invoice", "2fa", "order$item", "type"} {
+		if _, err := ParseContext(name); err == nil {
+			t.Errorf("Expected an error for %q", name)
+		}
+	}
+}
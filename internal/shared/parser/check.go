@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// errNotLoaded is returned for every import when a file is type-checked
+// on its own. The type checker then stands in a fake package, so the uses
+// of imported packages are not reported.
+var errNotLoaded = errors.New("imports are not loaded")
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// typeErrors type-checks src as a file of its own and counts its errors
+// by message. The failed imports, the names declared in the other files of
+// the package, which are undefined here, and unused variables and imports,
+// which a later edit may use, are left out. What remains can still be an
+// error the file had before, which is why callers compare the errors of
+// two versions of a file rather than look at one.
+func typeErrors(filePath string, src []byte) (map[string]int, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.AllErrors)
+	if err != nil {
+		return nil, err
+	}
+	errs := map[string]int{}
+	config := types.Config{
+		Importer: importerFunc(func(string) (*types.Package, error) { return nil, errNotLoaded }),
+		Error: func(err error) {
+			typeErr, ok := err.(types.Error)
+			if !ok || strings.Contains(typeErr.Msg, errNotLoaded.Error()) ||
+				strings.Contains(typeErr.Msg, "undefined") ||
+				typeErr.Soft && strings.Contains(typeErr.Msg, "not used") {
+				return
+			}
+			errs[typeErr.Msg]++
+		},
+	}
+	config.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	return errs, nil
+}
+
+// validate reports the errors content has that the file did not have when
+// it was read, such as a variable declared twice in the same function.
+func (r *astParser) validate(content []byte) error {
+	before, err := typeErrors(r.filePath, r.orig)
+	if err != nil {
+		// there is nothing to compare a file that did not parse with
+		before = map[string]int{}
+	}
+	after, err := typeErrors(r.filePath, content)
+	if err != nil {
+		return err
+	}
+
+	introduced := []string{}
+	for msg, n := range after {
+		if n > before[msg] {
+			introduced = append(introduced, msg)
+		}
+	}
+	if len(introduced) > 0 {
+		slices.Sort(introduced)
+		return fmt.Errorf("the edits of %s do not compile: %s", r.filePath, strings.Join(introduced, "; "))
+	}
+	return nil
+}
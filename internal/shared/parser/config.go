@@ -0,0 +1,299 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/naming"
+)
+
+// ConfigFile is the file of a generated project that declares AppConfig
+// and loads it.
+const ConfigFile = "internal/shared/config/config.go"
+
+// AppConfigType is the configuration struct the sections are fields of.
+const AppConfigType = "AppConfig"
+
+// LoadFunc is the function that registers the defaults of the settings.
+const LoadFunc = "Load"
+
+// ConfigSection is a nested struct of AppConfig, such as
+// JWT JWTConfig `mapstructure:"JWT"`.
+type ConfigSection struct {
+	// Name is the field of the section in AppConfig. The struct of the
+	// section is Name followed by Config.
+	Name     string
+	Settings []ConfigSetting
+}
+
+// ConfigSetting is a field of a section.
+type ConfigSetting struct {
+	// Name is the field of the setting, e.g. AccessTokenExp.
+	Name string
+	// Type is the Go type of the field, e.g. time.Duration.
+	Type string
+	// Key is the key of the setting in the section, e.g.
+	// JWT_ACCESS_TOKEN_EXP, which is set by the JWT.JWT_ACCESS_TOKEN_EXP
+	// environment variable.
+	Key string
+	// Default is the value of the setting as it is written in an
+	// environment file, e.g. 24h.
+	Default string
+	// Description documents the field.
+	Description string
+}
+
+// Tag returns the key of the section in the configuration, e.g. APP_COOKIE
+// for AppCookie.
+func (c ConfigSection) Tag() string {
+	return strings.ToUpper(naming.Snake(c.Name))
+}
+
+// EnvKey returns the environment variable of setting in the section.
+func (c ConfigSection) EnvKey(setting ConfigSetting) string {
+	return c.Tag() + "." + setting.Key
+}
+
+// AddConfig adds section to AppConfig, with the settings its struct does
+// not have yet, and registers the defaults Load does not have yet before
+// viper.AutomaticEnv(). It returns the environment variables of the
+// settings it added.
+func (r *astParser) AddConfig(section ConfigSection) ([]string, error) {
+	if r.findStruct(AppConfigType) == nil {
+		return nil, fmt.Errorf("%s has no %s struct", r.filePath, AppConfigType)
+	}
+	typeName := section.Name + "Config"
+
+	// the imports go first, since they move the structs
+	types, err := r.settingTypes(section, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	appConfig := r.findStruct(AppConfigType)
+	if !hasField(appConfig, section.Name) {
+		field := fmt.Sprintf("%s %s `mapstructure:%q`", section.Name, typeName, section.Tag())
+		indent := r.fieldIndent(appConfig)
+		if err := r.insert(r.offset(appConfig.Fields.Closing), indent+field+"\n"); err != nil {
+			return nil, fmt.Errorf("failed to add %s to %s: %w", section.Name, AppConfigType, err)
+		}
+	}
+
+	sectionStruct := r.findStruct(typeName)
+	indent := "\t"
+	if sectionStruct != nil {
+		indent = r.fieldIndent(sectionStruct)
+	}
+	fields := ""
+	for _, setting := range section.Settings {
+		typ, ok := types[setting.Name]
+		if !ok {
+			continue
+		}
+		if setting.Description != "" {
+			fields += indent + "// " + setting.Description + "\n"
+		}
+		fields += fmt.Sprintf("%s%s %s `mapstructure:%q`\n", indent, setting.Name, typ, setting.Key)
+	}
+	switch {
+	case fields == "":
+	case sectionStruct != nil:
+		if err := r.insert(r.offset(sectionStruct.Fields.Closing), fields); err != nil {
+			return nil, fmt.Errorf("failed to add settings to %s: %w", typeName, err)
+		}
+	default:
+		if err := r.addDecl(fmt.Sprintf("type %s struct {\n%s}", typeName, fields), LoadFunc); err != nil {
+			return nil, fmt.Errorf("failed to declare %s: %w", typeName, err)
+		}
+	}
+
+	return r.addDefaults(section)
+}
+
+// addDefaults registers the defaults of the settings of section that Load
+// does not have yet, as a block before viper.AutomaticEnv().
+func (r *astParser) addDefaults(section ConfigSection) ([]string, error) {
+	funcDecl := r.findFunc(LoadFunc)
+	if funcDecl == nil {
+		return nil, r.CheckFunction(LoadFunc)
+	}
+
+	defaults := map[string]bool{}
+	for _, stmt := range funcDecl.Body.List {
+		if key, ok := setDefault(stmt); ok {
+			defaults[key] = true
+		}
+	}
+	added := []string{}
+	codes := []string{}
+	for _, setting := range section.Settings {
+		key := section.EnvKey(setting)
+		if defaults[key] {
+			continue
+		}
+		added = append(added, key)
+		codes = append(codes, fmt.Sprintf("viper.SetDefault(%q, %s)", key, defaultValue(setting)))
+	}
+	if len(codes) == 0 {
+		return added, nil
+	}
+
+	index := len(funcDecl.Body.List)
+	for i, stmt := range funcDecl.Body.List {
+		if r.matches(Target{Match: "viper.AutomaticEnv()"}, stmt) {
+			index = i
+			break
+		}
+	}
+	// a blank line sets the section apart from the defaults before it
+	after := r.offset(funcDecl.Body.Lbrace) + 1
+	if index > 0 {
+		after = r.offset(funcDecl.Body.List[index-1].End())
+	}
+	indent := r.stmtIndent(funcDecl.Body, index)
+	text := "\n\n" + indent + strings.Join(codes, "\n"+indent)
+	if err := r.insert(r.lineEnd(after), text); err != nil {
+		return nil, fmt.Errorf("failed to add defaults in %s: %w", r.filePath, err)
+	}
+	return added, nil
+}
+
+// settingTypes returns the types of the settings of section the struct
+// typeName does not have yet by their names, importing the packages they
+// refer to.
+func (r *astParser) settingTypes(section ConfigSection, typeName string) (map[string]string, error) {
+	sectionStruct := r.findStruct(typeName)
+	added := []ConfigSetting{}
+	for _, setting := range section.Settings {
+		if sectionStruct == nil || !hasField(sectionStruct, setting.Name) {
+			added = append(added, setting)
+		}
+	}
+	types := map[string]string{}
+	for _, setting := range added {
+		typ, err := r.qualifyType(setting.Type)
+		if err != nil {
+			return nil, fmt.Errorf("the type of %s: %w", setting.Name, err)
+		}
+		types[setting.Name] = typ
+	}
+	return types, nil
+}
+
+// qualifyType imports the packages the qualified identifiers of typ refer
+// to by their names, such as time for map[string]time.Duration, so settings
+// can only have the types of standard packages besides the predeclared
+// ones. It returns typ with each package referred to by the name it is
+// imported as, which is time2 when time is taken.
+func (r *astParser) qualifyType(typ string) (string, error) {
+	fset := token.NewFileSet()
+	expr, err := parser.ParseExprFrom(fset, "", typ, 0)
+	if err != nil {
+		return "", fmt.Errorf("invalid type %s: %w", typ, err)
+	}
+	pkgs := []*ast.Ident{}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if selExpr, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := selExpr.X.(*ast.Ident); ok {
+				pkgs = append(pkgs, ident)
+			}
+		}
+		return true
+	})
+	names := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		if names[i], _, err = r.AddImport(pkg.Name, ""); err != nil {
+			return "", err
+		}
+	}
+	// from the last qualifier, so the offsets of the ones before it hold
+	for i := len(pkgs) - 1; i >= 0; i-- {
+		offset := fset.Position(pkgs[i].Pos()).Offset
+		typ = typ[:offset] + names[i] + typ[offset+len(pkgs[i].Name):]
+	}
+	return typ, nil
+}
+
+// defaultValue returns the Go expression of the default of setting: a
+// number or a boolean as it is, anything else as a string, which viper
+// converts to the type of the field.
+func defaultValue(setting ConfigSetting) string {
+	switch setting.Type {
+	case "int", "int64", "float64", "bool":
+		return setting.Default
+	}
+	return strconv.Quote(setting.Default)
+}
+
+// setDefault returns the key of stmt when it is a viper.SetDefault call.
+func setDefault(stmt ast.Stmt) (string, bool) {
+	exprStmt, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return "", false
+	}
+	call, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return "", false
+	}
+	selExpr, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selExpr.Sel.Name != "SetDefault" || callReceiver(call) != "viper" {
+		return "", false
+	}
+	return stringLit(call.Args[0])
+}
+
+// findStruct returns the struct type declared as name.
+func (r *astParser) findStruct(name string) *ast.StructType {
+	for _, decl := range r.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok && typeSpec.Name.Name == name {
+				return structType
+			}
+		}
+	}
+	return nil
+}
+
+// fieldIndent returns the indentation of the fields of structType, that of
+// its last field.
+func (r *astParser) fieldIndent(structType *ast.StructType) string {
+	elem := -1
+	if fields := structType.Fields.List; len(fields) > 0 {
+		elem = r.offset(fields[len(fields)-1].Pos())
+	}
+	return r.blockIndent(r.offset(structType.Fields.Opening), elem)
+}
+
+// hasField reports whether structType has a field name.
+func hasField(structType *ast.StructType, name string) bool {
+	for _, field := range structType.Fields.List {
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addDecl inserts the top-level declaration code before the function
+// before, or at the end of the file when there is none.
+func (r *astParser) addDecl(code string, before string) error {
+	if funcDecl := r.findFunc(before); funcDecl != nil {
+		pos := funcDecl.Pos()
+		if funcDecl.Doc != nil {
+			pos = funcDecl.Doc.Pos()
+		}
+		return r.insert(r.offset(pos), code+"\n\n")
+	}
+	return r.insert(len(r.src), "\n"+code+"\n")
+}
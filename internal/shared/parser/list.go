@@ -0,0 +1,370 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+)
+
+// Route is a route registered in Routes, or in a function Routes passes
+// the router or a group to.
+type Route struct {
+	// Method is the router method, e.g. GET, or the method of a Handle call.
+	Method string `json:"method"`
+	// Path is the full path, with the prefixes of the groups.
+	Path string `json:"path"`
+	// Handler is the expression of the last handler, e.g. authHandler.Login.
+	Handler string `json:"handler"`
+	// Middlewares are the expressions of the handlers that run before
+	// Handler: those of the groups, then those of the route.
+	Middlewares []string `json:"middlewares"`
+	// Group is the group the route is registered on, e.g. adminGroup, or ""
+	// for the router itself.
+	Group string `json:"group"`
+	// File and Line are where the route is registered.
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// ListRoutes returns the routes of Routes in RouterFile in the order they
+// are registered. It follows the calls that pass the router or a group to
+// a function or a method, either in the package of the caller or in a
+// package of module, which must be listable in files to be found. A file
+// it looks in that cannot be read or parsed is an error naming the file,
+// rather than routes left out.
+func ListRoutes(files file.File, module string) ([]Route, error) {
+	l := &routeLister{
+		files:   files,
+		module:  module,
+		parsers: map[string]*astParser{},
+		walking: map[*ast.FuncDecl]bool{},
+		routes:  []Route{},
+	}
+	routerParser, err := l.parser(RouterFile)
+	if err != nil {
+		return nil, err
+	}
+	funcDecl := routerParser.findFunc(RoutesFunc)
+	if funcDecl == nil {
+		return nil, routerParser.CheckRoutes()
+	}
+	l.walk(routerParser, funcDecl, map[string]RouteGroup{RouteTarget.Receiver: {}})
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.routes, nil
+}
+
+type routeLister struct {
+	files  file.File
+	module string
+	// parsers are the files read so far, by path.
+	parsers map[string]*astParser
+	// walking are the functions being walked, so a recursive call is
+	// walked once.
+	walking map[*ast.FuncDecl]bool
+	routes  []Route
+	// err is the first file that could not be listed or parsed.
+	err error
+}
+
+// parser returns the parsed file at filePath, reading it the first time.
+func (l *routeLister) parser(filePath string) (*astParser, error) {
+	if p, ok := l.parsers[filePath]; ok {
+		return p, nil
+	}
+	p, err := NewASTParser(l.files, filePath)
+	if err != nil {
+		return nil, err
+	}
+	l.parsers[filePath] = p.(*astParser)
+	return l.parsers[filePath], nil
+}
+
+// walk collects the routes of funcDecl, where groups are the variables
+// that are the router or a group.
+func (l *routeLister) walk(p *astParser, funcDecl *ast.FuncDecl, groups map[string]RouteGroup) {
+	if l.walking[funcDecl] {
+		return
+	}
+	l.walking[funcDecl] = true
+	defer delete(l.walking, funcDecl)
+	l.walkStmts(p, funcDecl.Body.List, groups, map[string]ast.Expr{})
+}
+
+// walkStmts collects the routes of stmts, where values are the expressions
+// the other variables are assigned. Blocks are walked in place, as they
+// are only used to set the routes of a group apart.
+func (l *routeLister) walkStmts(p *astParser, stmts []ast.Stmt, groups map[string]RouteGroup, values map[string]ast.Expr) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.BlockStmt:
+			l.walkStmts(p, s.List, groups, values)
+		case *ast.AssignStmt:
+			if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+				continue
+			}
+			ident, ok := s.Lhs[0].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if group, ok := l.group(p, s.Rhs[0], groups); ok {
+				if _, isGroupCall := s.Rhs[0].(*ast.CallExpr); isGroupCall {
+					group.Name = ident.Name
+				}
+				groups[ident.Name] = group
+			} else {
+				values[ident.Name] = s.Rhs[0]
+			}
+		case *ast.ExprStmt:
+			if call, ok := s.X.(*ast.CallExpr); ok {
+				l.call(p, call, groups, values)
+			}
+		}
+	}
+}
+
+// call collects the route call registers, or the middlewares it adds to a
+// group, or walks the function it passes a group to.
+func (l *routeLister) call(p *astParser, call *ast.CallExpr, groups map[string]RouteGroup, values map[string]ast.Expr) {
+	if selExpr, ok := call.Fun.(*ast.SelectorExpr); ok {
+		if group, ok := l.group(p, selExpr.X, groups); ok {
+			method, args := selExpr.Sel.Name, call.Args
+			switch {
+			case method == "Use":
+				// Use applies to the routes registered after it
+				if ident, ok := selExpr.X.(*ast.Ident); ok {
+					group.Middlewares = append(slices.Clip(group.Middlewares), p.exprs(args)...)
+					groups[ident.Name] = group
+				}
+				return
+			case method == "Handle" && len(args) > 0:
+				method = p.expr(args[0])
+				if value, ok := stringLit(args[0]); ok {
+					method = value
+				}
+				args = args[1:]
+			case !slices.Contains(HTTPMethods, method):
+				return
+			}
+			if len(args) < 2 {
+				return
+			}
+			relative := p.expr(args[0])
+			if value, ok := stringLit(args[0]); ok {
+				relative = value
+			}
+			handlers := args[1:]
+			l.routes = append(l.routes, Route{
+				Method:      method,
+				Path:        joinPaths(group.Prefix, relative),
+				Handler:     p.expr(handlers[len(handlers)-1]),
+				Middlewares: append(append([]string{}, group.Middlewares...), p.exprs(handlers[:len(handlers)-1])...),
+				Group:       group.Name,
+				File:        p.filePath,
+				Line:        p.fset.Position(call.Pos()).Line,
+			})
+			return
+		}
+	}
+
+	passed := map[int]RouteGroup{}
+	for i, arg := range call.Args {
+		if group, ok := l.group(p, arg, groups); ok {
+			passed[i] = group
+		}
+	}
+	if len(passed) == 0 {
+		return
+	}
+	callee, funcDecl := l.resolve(p, call.Fun, values)
+	if funcDecl == nil {
+		return
+	}
+	params := map[string]RouteGroup{}
+	i := 0
+	for _, field := range funcDecl.Type.Params.List {
+		for _, ident := range field.Names {
+			if group, ok := passed[i]; ok {
+				params[ident.Name] = group
+			}
+			i++
+		}
+		if len(field.Names) == 0 {
+			i++
+		}
+	}
+	l.walk(callee, funcDecl, params)
+}
+
+// group returns the group expr registers routes on: a variable of groups,
+// or a chain of Group calls on one.
+func (l *routeLister) group(p *astParser, expr ast.Expr, groups map[string]RouteGroup) (RouteGroup, bool) {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		group, ok := groups[x.Name]
+		return group, ok
+	case *ast.CallExpr:
+		selExpr, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok || selExpr.Sel.Name != "Group" || len(x.Args) == 0 {
+			return RouteGroup{}, false
+		}
+		parent, ok := l.group(p, selExpr.X, groups)
+		if !ok {
+			return RouteGroup{}, false
+		}
+		relative := p.expr(x.Args[0])
+		if value, ok := stringLit(x.Args[0]); ok {
+			relative = value
+		}
+		return RouteGroup{
+			Name:        p.expr(x),
+			Prefix:      joinPaths(parent.Prefix, relative),
+			Middlewares: append(slices.Clone(parent.Middlewares), p.exprs(x.Args[1:])...),
+		}, true
+	}
+	return RouteGroup{}, false
+}
+
+// resolve returns the function fun calls and the file it is declared in:
+// a function of the package of p, a function of an imported package of
+// the module, or a method of the package of the value of the variable it
+// is called on, e.g. the package of delivery for
+// handler := delivery.NewHandler().
+func (l *routeLister) resolve(p *astParser, fun ast.Expr, values map[string]ast.Expr) (*astParser, *ast.FuncDecl) {
+	dir := path.Dir(p.filePath)
+	switch x := fun.(type) {
+	case *ast.Ident:
+		return l.find(dir, p, func(q *astParser) *ast.FuncDecl { return q.findFunc(x.Name) })
+	case *ast.SelectorExpr:
+		ident, ok := x.X.(*ast.Ident)
+		if !ok {
+			return nil, nil
+		}
+		if pkgDir, ok := l.importDir(p, ident.Name); ok {
+			return l.find(pkgDir, nil, func(q *astParser) *ast.FuncDecl { return q.findFunc(x.Sel.Name) })
+		}
+		if value, ok := values[ident.Name]; ok {
+			if pkg := qualifier(value); pkg != "" {
+				pkgDir, ok := l.importDir(p, pkg)
+				if !ok {
+					return nil, nil
+				}
+				dir = pkgDir
+			}
+		}
+		return l.find(dir, p, func(q *astParser) *ast.FuncDecl { return q.findMethod(x.Sel.Name) })
+	}
+	return nil, nil
+}
+
+// find returns the first function match finds in the files of dir,
+// looking in p first when it is one of them.
+func (l *routeLister) find(dir string, p *astParser, match func(*astParser) *ast.FuncDecl) (*astParser, *ast.FuncDecl) {
+	if p != nil {
+		if funcDecl := match(p); funcDecl != nil {
+			return p, funcDecl
+		}
+	}
+	names, err := l.files.ListFiles(dir)
+	if err != nil {
+		// a filesystem that cannot list has only the files of the router
+		if !errors.Is(err, errors.ErrUnsupported) && l.err == nil {
+			l.err = fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+		return nil, nil
+	}
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		q, err := l.parser(path.Join(dir, name))
+		if err != nil {
+			if l.err == nil {
+				l.err = err
+			}
+			continue
+		}
+		if q == p {
+			continue
+		}
+		if funcDecl := match(q); funcDecl != nil {
+			return q, funcDecl
+		}
+	}
+	return nil, nil
+}
+
+// importDir returns the directory of the package of the module p imports
+// as name.
+func (l *routeLister) importDir(p *astParser, name string) (string, bool) {
+	for _, importSpec := range p.file.Imports {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			continue
+		}
+		importName := path.Base(importPath)
+		if importSpec.Name != nil {
+			importName = importSpec.Name.Name
+		}
+		if importName != name {
+			continue
+		}
+		dir, ok := strings.CutPrefix(importPath, l.module+"/")
+		return dir, ok
+	}
+	return "", false
+}
+
+// qualifier returns the package a value is made with, e.g. delivery for
+// delivery.NewHandler() or &delivery.Handler{}.
+func qualifier(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.UnaryExpr:
+		return qualifier(x.X)
+	case *ast.CallExpr:
+		return qualifier(x.Fun)
+	case *ast.CompositeLit:
+		return qualifier(x.Type)
+	case *ast.SelectorExpr:
+		if ident, ok := x.X.(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// findMethod returns the method name of any type of the file.
+func (r *astParser) findMethod(name string) *ast.FuncDecl {
+	for _, decl := range r.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if ok && funcDecl.Recv != nil && funcDecl.Name.Name == name && funcDecl.Body != nil {
+			return funcDecl
+		}
+	}
+	return nil
+}
+
+// expr returns the source of expr on one line, with the body of a function
+// literal left out.
+func (r *astParser) expr(expr ast.Expr) string {
+	if funcLit, ok := expr.(*ast.FuncLit); ok {
+		return r.expr(funcLit.Type) + " {...}"
+	}
+	return strings.Join(strings.Fields(string(r.src[r.offset(expr.Pos()):r.offset(expr.End())])), " ")
+}
+
+// exprs returns the sources of exprs.
+func (r *astParser) exprs(exprs []ast.Expr) []string {
+	sources := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		sources = append(sources, r.expr(expr))
+	}
+	return sources
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"strings"
+)
+
+// MigrationsFile is the file of a generated project that lists the schemas
+// the database migrates when the server starts.
+const MigrationsFile = "cmd/server/migrations.go"
+
+// SchemasFunc is the function that returns the schemas to migrate.
+const SchemasFunc = "Schemas"
+
+// AddMigration adds &pkg.Schema{} to the list SchemasFunc returns, where pkg
+// is the name AddImport imports importPath as with alias. It returns the
+// element and reports whether the list did not have it yet.
+func (r *astParser) AddMigration(importPath string, alias string, schema string) (string, bool, error) {
+	if _, err := r.schemas(); err != nil {
+		return "", false, err
+	}
+	name, _, err := r.AddImport(importPath, alias)
+	if err != nil {
+		return "", false, err
+	}
+	code := fmt.Sprintf("&%s.%s{}", name, schema)
+	expr, err := parser.ParseExpr(code)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to parse schema %q: %w", code, err)
+	}
+
+	// the import may have moved the list
+	list, err := r.schemas()
+	if err != nil {
+		return "", false, err
+	}
+	for _, elt := range list.Elts {
+		if isNodeEqual(elt, expr) {
+			return code, false, nil
+		}
+	}
+
+	rbrace := r.offset(list.Rbrace)
+	lineStart := strings.LastIndexByte(string(r.src[:rbrace]), '\n') + 1
+	before := strings.TrimSpace(string(r.src[lineStart:rbrace]))
+	elem := -1
+	if len(list.Elts) > 0 {
+		elem = r.offset(list.Elts[len(list.Elts)-1].Pos())
+	}
+	indent := r.blockIndent(r.offset(list.Lbrace), elem)
+	switch {
+	case before == "":
+		// the closing brace is on a line of its own
+		err = r.insert(lineStart, indent+code+",\n")
+	case len(list.Elts) == 0:
+		err = r.insert(rbrace, "\n"+indent+code+",\n"+strings.TrimSuffix(indent, "\t"))
+	case strings.HasSuffix(before, ","):
+		err = r.insert(rbrace, " "+code)
+	default:
+		err = r.insert(rbrace, ", "+code)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to add schema in %s: %w", r.filePath, err)
+	}
+	return code, true, nil
+}
+
+// schemas returns the list of schemas SchemasFunc returns.
+func (r *astParser) schemas() (*ast.CompositeLit, error) {
+	funcDecl := r.findFunc(SchemasFunc)
+	if funcDecl == nil {
+		return nil, r.CheckFunction(SchemasFunc)
+	}
+	for _, stmt := range funcDecl.Body.List {
+		returnStmt, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(returnStmt.Results) != 1 {
+			continue
+		}
+		if list, ok := returnStmt.Results[0].(*ast.CompositeLit); ok {
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("%s in %s does not return a list of schemas", SchemasFunc, r.filePath)
+}
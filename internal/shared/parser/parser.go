@@ -1,174 +1,457 @@
+// Package parser finds and edits the declarations of the Go files of a
+// project, such as its routes, dependencies and configuration. The syntax
+// tree is only read: an edit splices source text at offsets taken from the
+// tree, indented like the code around it, and parses the result again.
+// Comments, blank lines and layout outside the spliced text stay as they
+// were, which a printed tree does not guarantee, and go/format aligns the
+// spliced text before the file is written.
 package parser
 
 import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/format"
 	"go/parser"
 	"go/printer"
 	"go/token"
-	"log"
-	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+)
+
+// RouterFile is the file of a generated project that registers its routes.
+const RouterFile = "cmd/server/router.go"
+
+// RoutesFunc is the function that dependencies and routes are added to.
+const RoutesFunc = "Routes"
+
+// Anchors place inserted statements relative to the body of the target
+// function.
+const (
+	// AnchorStart inserts at the start of the body.
+	AnchorStart = "start"
+	// AnchorEnd inserts at the end of the body.
+	AnchorEnd = "end"
+	// AnchorBefore inserts before the first matching statement.
+	AnchorBefore = "before"
+	// AnchorAfter inserts after the last matching statement.
+	AnchorAfter = "after"
 )
 
+// Target is where statements are inserted in a file.
+type Target struct {
+	// Function is the name of the function, or Type.Method for a method.
+	Function string
+	// Receiver is the variable whose declaration and method calls are
+	// matched by the before and after anchors, e.g. router for
+	// router.GET(...) and router.Group("/api").GET(...).
+	Receiver string
+	// Anchor is one of AnchorStart, AnchorEnd, AnchorBefore or AnchorAfter.
+	Anchor string
+	// Match, when set, matches the statements that contain it instead of
+	// the calls of Receiver.
+	Match string
+}
+
+// DependencyTarget is where AddDependencies inserts: before the first
+// router call in Routes.
+var DependencyTarget = Target{Function: RoutesFunc, Receiver: "router", Anchor: AnchorBefore}
+
+// RouteTarget is where AddRoute inserts: after the last router call in
+// Routes.
+var RouteTarget = Target{Function: RoutesFunc, Receiver: "router", Anchor: AnchorAfter}
+
 type ASTParser interface {
+	AddConfig(section ConfigSection) ([]string, error)
 	AddDependencies(codes []string) error
-	AddImport(importPath string, alias string)
-	AddRoute(route string)
-	WriteFile()
+	AddImport(importPath string, alias string) (string, bool, error)
+	AddMigration(importPath string, alias string, schema string) (string, bool, error)
+	AddRoute(route string) error
+	AddRouteGroup(target Target, group RouteGroup) (string, bool, error)
+	AddStatements(target Target, codes []string) (bool, error)
+	CheckFunction(name string) error
+	CheckRoutes() error
+	Content() ([]byte, error)
+	FindRouteGroup(target Target, prefix string) (RouteGroup, bool)
+	MoveRoutes(target Target, prefix string, group string) ([]string, error)
+	PruneImports(names []string) ([]string, error)
+	RemoveDependency(dependency string) (bool, error)
+	RemoveImport(importPath string) (bool, error)
+	RemoveRoute(method string, path string) (bool, error)
+	WriteFile() error
 }
 
+// astParser keeps the source of the file next to its syntax tree. The tree
+// is only used to find where an edit goes; every edit is spliced into the
+// source and the source is parsed again, so the comments, blank lines and
+// formatting a developer wrote are kept as they are.
 type astParser struct {
+	files    file.File
 	filePath string
-	file     *ast.File
-	fset     *token.FileSet
+	// orig is the source as it was read, which WriteFile validates the
+	// edits against.
+	orig []byte
+	src  []byte
+	file *ast.File
+	fset *token.FileSet
 }
 
+// NewASTParser parses filePath, read from files. WriteFile writes the
+// edited file back to files.
 func NewASTParser(
+	files file.File,
 	filePath string,
-) ASTParser {
-	src, err := os.ReadFile(filePath)
+) (ASTParser, error) {
+	src, err := files.ReadFile(filePath)
 	if err != nil {
-		log.Fatalf("Error reading file: %v", err)
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	r := &astParser{
+		files:    files,
+		filePath: filePath,
+		orig:     src,
+	}
+	if err := r.parse(src); err != nil {
+		return nil, fmt.Errorf("error parsing file: %w", err)
 	}
+	return r, nil
+}
 
+// parse replaces the source and the syntax tree with src.
+func (r *astParser) parse(src []byte) error {
 	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filePath, src, 0)
+	file, err := parser.ParseFile(fset, r.filePath, src, parser.ParseComments)
 	if err != nil {
-		log.Fatalf("Error parsing file: %v", err)
+		return err
 	}
+	r.src = src
+	r.file = file
+	r.fset = fset
+	return nil
+}
 
-	return &astParser{
-		file:     file,
-		fset:     fset,
-		filePath: filePath,
+// insert splices text into the source at offset and parses the result. The
+// source is left unchanged when the result does not parse.
+func (r *astParser) insert(offset int, text string) error {
+	src := make([]byte, 0, len(r.src)+len(text))
+	src = append(src, r.src[:offset]...)
+	src = append(src, text...)
+	src = append(src, r.src[offset:]...)
+	return r.parse(src)
+}
+
+// offset returns the byte offset of pos in the source.
+func (r *astParser) offset(pos token.Pos) int {
+	return r.fset.Position(pos).Offset
+}
+
+// lineEnd returns the offset of the newline that ends the line of offset,
+// or the end of the source.
+func (r *astParser) lineEnd(offset int) int {
+	if i := bytes.IndexByte(r.src[offset:], '\n'); i >= 0 {
+		return offset + i
 	}
+	return len(r.src)
 }
 
-func (r *astParser) AddImport(importPath string, alias string) {
-	// Check if this exact import (path and alias) already exists to avoid duplicates.
+// blockIndent returns the indentation of the lines of the block opened at
+// the offset open: that of the line of elem, the offset of an element of
+// the block, when the element starts its line, or else one tab more than
+// the line of open. A negative elem is a block without elements.
+func (r *astParser) blockIndent(open int, elem int) string {
+	if elem >= 0 {
+		lineStart := bytes.LastIndexByte(r.src[:elem], '\n') + 1
+		if len(bytes.TrimSpace(r.src[lineStart:elem])) == 0 {
+			return string(r.src[lineStart:elem])
+		}
+	}
+	lineStart := bytes.LastIndexByte(r.src[:open], '\n') + 1
+	end := lineStart
+	for end < open && (r.src[end] == ' ' || r.src[end] == '\t') {
+		end++
+	}
+	return string(r.src[lineStart:end]) + "\t"
+}
+
+// stmtIndent returns the indentation of the statements of body around
+// index, the statement before it or else the one at it.
+func (r *astParser) stmtIndent(body *ast.BlockStmt, index int) string {
+	elem := -1
+	if index > 0 {
+		elem = r.offset(body.List[index-1].Pos())
+	} else if index < len(body.List) {
+		elem = r.offset(body.List[index].Pos())
+	}
+	return r.blockIndent(r.offset(body.Lbrace), elem)
+}
+
+// CheckRoutes reports whether the file has a Routes function with a body
+// that dependencies and routes can be added to.
+func (r *astParser) CheckRoutes() error {
+	return r.CheckFunction(RoutesFunc)
+}
+
+// CheckFunction reports whether the file has the function name, or the
+// method Type.Method, with a body that statements can be added to.
+func (r *astParser) CheckFunction(name string) error {
+	if r.findFunc(name) == nil {
+		return fmt.Errorf("function %s not found in %s", name, r.filePath)
+	}
+	return nil
+}
+
+func (r *astParser) findFunc(name string) *ast.FuncDecl {
+	recvType, funcName, isMethod := strings.Cut(name, ".")
+	if !isMethod {
+		funcName = name
+	}
+	for _, decl := range r.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Name.Name != funcName || funcDecl.Body == nil {
+			continue
+		}
+		if !isMethod && funcDecl.Recv == nil {
+			return funcDecl
+		}
+		if isMethod && funcDecl.Recv != nil && receiverType(funcDecl.Recv.List[0].Type) == recvType {
+			return funcDecl
+		}
+	}
+	return nil
+}
+
+// receiverType returns the type name of a method receiver, without the
+// pointer and type parameters.
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// AddImport imports importPath as alias, or under its package name when
+// alias is empty, and returns the name the file refers to the package by
+// and whether the import was added. An import of the same path is reused
+// whatever its name. When the name is taken by another import or a
+// top-level declaration, a unique alias is chosen by appending a number,
+// e.g. authApp2.
+func (r *astParser) AddImport(importPath string, alias string) (string, bool, error) {
 	for _, i := range r.file.Imports {
-		// Check path match
-		if i.Path.Value == `"`+importPath+`"` {
-			// If we want an alias, check if it has one and if it matches.
-			if alias != "" {
-				if i.Name != nil && i.Name.Name == alias {
-					return // Exact match found, do nothing.
-				}
-			} else {
-				// If we don't want an alias, check that it doesn't have one.
-				if i.Name == nil {
-					return // Exact match found, do nothing.
-				}
-			}
+		if i.Path.Value != strconv.Quote(importPath) {
+			continue
+		}
+		if name, ok := importName(i); ok && name != "_" && name != "." {
+			return name, false, nil
 		}
 	}
 
-	// Create a new import spec
-	newImport := &ast.ImportSpec{
-		Path: &ast.BasicLit{
-			Kind:  token.STRING,
-			Value: `"` + importPath + `"`,
-		},
+	name := alias
+	if pkg, ok := packageName(importPath); name == "" && ok {
+		name = pkg
+	}
+	if name != "" && r.nameTaken(name) {
+		base := name
+		for n := 2; r.nameTaken(name); n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		alias = name
 	}
 
+	spec := strconv.Quote(importPath)
 	if alias != "" {
-		newImport.Name = ast.NewIdent(alias)
+		spec = alias + " " + spec
 	}
 
-	// Find the import declaration block
+	// Find the last import declaration
 	var importDecl *ast.GenDecl
 	for _, decl := range r.file.Decls {
 		genDecl, ok := decl.(*ast.GenDecl)
 		if ok && genDecl.Tok == token.IMPORT {
 			importDecl = genDecl
-			break
 		}
 	}
 
-	// Add the new import to the existing block or create a new one
-	if importDecl != nil {
-		importDecl.Specs = append(importDecl.Specs, newImport)
-	} else {
-		// No import block found, create a new one
-		importDecl = &ast.GenDecl{
-			Tok:   token.IMPORT,
-			Specs: []ast.Spec{newImport},
+	var err error
+	switch {
+	case importDecl != nil && importDecl.Rparen.IsValid() && len(importDecl.Specs) > 0:
+		err = r.addImportSpec(importDecl, importPath, spec)
+	case importDecl != nil && importDecl.Rparen.IsValid():
+		err = r.insert(r.offset(importDecl.Rparen), r.blockIndent(r.offset(importDecl.Lparen), -1)+spec+"\n")
+	case importDecl != nil:
+		// turn the single import into a block with both, in one group when
+		// they belong together
+		start, end := r.offset(importDecl.Pos()), r.offset(importDecl.End())
+		existing := string(r.src[r.offset(importDecl.Specs[0].Pos()):end])
+		lines := []string{existing, spec}
+		switch {
+		case importGroup(r.importGroups(importDecl), importPath) == 0:
+		case isStdImport(importPath):
+			lines = []string{spec, "", existing}
+		default:
+			lines = []string{existing, "", spec}
+		}
+		block := "import (\n"
+		for _, line := range lines {
+			if line != "" {
+				block += "\t" + line
+			}
+			block += "\n"
 		}
-		// Add the new import block to the top of the file's declarations
-		r.file.Decls = append([]ast.Decl{importDecl}, r.file.Decls...)
+		src := append([]byte{}, r.src[:start]...)
+		src = append(src, block+")"...)
+		err = r.parse(append(src, r.src[end:]...))
+	default:
+		err = r.insert(r.lineEnd(r.offset(r.file.Name.End())), "\n\nimport "+spec)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to import %s in %s: %w", importPath, r.filePath, err)
 	}
+	return name, true, nil
+}
 
+// isStdImport reports whether importPath is a package of the standard
+// library, whose first element has no dot.
+func isStdImport(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
 }
 
-func (r *astParser) AddRoute(route string) {
-	ast.Inspect(r.file, func(n ast.Node) bool {
-		funcDecl, ok := n.(*ast.FuncDecl)
-		if !ok || funcDecl.Name.Name != "Routes" {
-			return true
+// addImportSpec adds spec, the import of importPath, to the group of the
+// import block importDecl it belongs to, or else as a new group: before
+// the others for a standard package and after them for any other.
+func (r *astParser) addImportSpec(importDecl *ast.GenDecl, importPath string, spec string) error {
+	groups := r.importGroups(importDecl)
+	first := groups[0][0]
+	indent := r.blockIndent(r.offset(importDecl.Lparen), r.offset(first.Pos()))
+	index := importGroup(groups, importPath)
+	switch {
+	case index >= 0:
+		group := groups[index]
+		return r.insert(r.lineEnd(r.offset(group[len(group)-1].End())), "\n"+indent+spec)
+	case isStdImport(importPath):
+		start := r.offset(importStart(first))
+		lineStart := max(bytes.LastIndexByte(r.src[:start], '\n')+1, r.offset(importDecl.Lparen)+1)
+		return r.insert(lineStart, indent+spec+"\n\n")
+	default:
+		group := groups[len(groups)-1]
+		return r.insert(r.lineEnd(r.offset(group[len(group)-1].End())), "\n\n"+indent+spec)
+	}
+}
+
+// importGroups returns the imports of importDecl in the groups blank lines
+// separate them into.
+func (r *astParser) importGroups(importDecl *ast.GenDecl) [][]*ast.ImportSpec {
+	groups := [][]*ast.ImportSpec{}
+	lastLine := 0
+	for _, spec := range importDecl.Specs {
+		importSpec := spec.(*ast.ImportSpec)
+		if len(groups) == 0 || r.fset.Position(importStart(importSpec)).Line > lastLine+1 {
+			groups = append(groups, nil)
 		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], importSpec)
+		lastLine = r.fset.Position(importSpec.End()).Line
+	}
+	return groups
+}
 
-		insertionIndex := -1
-		// ... logic to find insertionIndex ...
-		for i, stmt := range funcDecl.Body.List {
-			if exprStmt, ok := stmt.(*ast.ExprStmt); ok {
-				if callExpr, ok := exprStmt.X.(*ast.CallExpr); ok {
-					if selExpr, ok := callExpr.Fun.(*ast.SelectorExpr); ok {
-						// here we will insert after the last router
-						if selExpr.X.(*ast.Ident).Name == "router" {
-							insertionIndex = i
-						}
-					}
-				}
+// importStart returns the position of importSpec with its doc comment.
+func importStart(importSpec *ast.ImportSpec) token.Pos {
+	if importSpec.Doc != nil {
+		return importSpec.Doc.Pos()
+	}
+	return importSpec.Pos()
+}
+
+// importGroup returns the index of the group importPath belongs to: the
+// last of those with the import sharing the most leading path elements
+// with it, such as the imports of the same module, or, for a standard
+// package sharing none, the first with a standard import. It returns -1
+// when there is none.
+func importGroup(groups [][]*ast.ImportSpec, importPath string) int {
+	index, most := -1, 0
+	for i, group := range groups {
+		for _, importSpec := range group {
+			specPath, err := strconv.Unquote(importSpec.Path.Value)
+			if err != nil {
+				continue
+			}
+			if shared := sharedElements(specPath, importPath); shared > 0 && shared >= most {
+				index, most = i, shared
 			}
 		}
-
-		if insertionIndex == -1 {
-			insertionIndex = len(funcDecl.Body.List)
+	}
+	if index >= 0 || !isStdImport(importPath) {
+		return index
+	}
+	for i, group := range groups {
+		for _, importSpec := range group {
+			if specPath, err := strconv.Unquote(importSpec.Path.Value); err == nil && isStdImport(specPath) {
+				return i
+			}
 		}
+	}
+	return -1
+}
 
-		insertionIndex++
+// sharedElements returns the number of leading path elements a and b
+// share.
+func sharedElements(a string, b string) int {
+	aElems, bElems := strings.Split(a, "/"), strings.Split(b, "/")
+	shared := 0
+	for shared < len(aElems) && shared < len(bElems) && aElems[shared] == bElems[shared] {
+		shared++
+	}
+	return shared
+}
 
-		originalStmts := funcDecl.Body.List
-		var newStmts []ast.Stmt
-		stmt, err := r.parseStmt(route)
-		if err != nil {
-			log.Fatalf("Failed to parse statement: %v %v", route, err)
-			return false
-		}
-		newStmts = append(newStmts, stmt)
-
-		// check if the code is already in the list
-		var cleanNewStmts []ast.Stmt
-		for _, newStmt := range newStmts {
-			var isExists = false
-			for _, stmt := range originalStmts {
-				isExists = r.isStmtExists(stmt, newStmt)
-				if isExists {
-					break
-				}
-			}
-			if !isExists {
-				cleanNewStmts = append(cleanNewStmts, newStmt)
-			}
+// nameTaken reports whether an import or a top-level declaration of the
+// file already uses name.
+func (r *astParser) nameTaken(name string) bool {
+	for _, i := range r.file.Imports {
+		if importName, ok := importName(i); ok && importName == name {
+			return true
 		}
+	}
+	return r.file.Scope.Lookup(name) != nil
+}
 
-		funcDecl.Body.List = append(
-			originalStmts[:insertionIndex],
-			append(cleanNewStmts, originalStmts[insertionIndex:]...)...)
+func (r *astParser) AddRoute(route string) error {
+	_, err := r.AddStatements(RouteTarget, []string{route})
+	return err
+}
 
-		return false // Stop searching
-	})
+// Content returns the edited file, formatted, once the edits are
+// validated.
+func (r *astParser) Content() ([]byte, error) {
+	content, err := format.Source(r.src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s: %w", r.filePath, err)
+	}
+	if err := r.validate(content); err != nil {
+		return nil, err
+	}
+	return content, nil
 }
 
-func (r *astParser) WriteFile() {
-	var buf bytes.Buffer
-	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
-	err := cfg.Fprint(&buf, r.fset, r.file) // Use Fprint from the configured printer
+// WriteFile writes the edited file back, formatted, once the edits are
+// validated. The file is left as it was when they introduce an error.
+func (r *astParser) WriteFile() error {
+	content, err := r.Content()
 	if err != nil {
-		log.Fatalf("Failed to format code: %v", err)
+		return err
 	}
-	os.WriteFile(r.filePath, buf.Bytes(), 0644)
+	return r.files.CreateFile(r.filePath, content)
 }
 
 // AddDependencies adds dependencies to the filepath.
@@ -182,131 +465,184 @@ func (r *astParser) WriteFile() {
 // Returns an error if the dependencies cannot be added due to parsing issues
 // or if the file structure is incompatible.
 func (r *astParser) AddDependencies(codes []string) error {
-	var parseErr error
-	ast.Inspect(r.file, func(n ast.Node) bool {
-		funcDecl, ok := n.(*ast.FuncDecl)
-		if !ok || funcDecl.Name.Name != "Routes" {
-			return true
-		}
+	_, err := r.AddStatements(DependencyTarget, codes)
+	return err
+}
+
+// AddStatements inserts the statements in codes that the target function
+// does not have yet at the anchor of target, and reports whether it
+// inserted any. The before and after anchors insert at the end of the body
+// when no statement matches.
+func (r *astParser) AddStatements(target Target, codes []string) (bool, error) {
+	funcDecl := r.findFunc(target.Function)
+	if funcDecl == nil {
+		return false, r.CheckFunction(target.Function)
+	}
 
-		insertionIndex := -1
-		// ... logic to find insertionIndex ...
-		for i, stmt := range funcDecl.Body.List {
-			if exprStmt, ok := stmt.(*ast.ExprStmt); ok {
-				if callExpr, ok := exprStmt.X.(*ast.CallExpr); ok {
-					if selExpr, ok := callExpr.Fun.(*ast.SelectorExpr); ok {
-						if selExpr.X.(*ast.Ident).Name == "router" {
-							insertionIndex = i
-							break
-						}
-					}
-				}
+	body := funcDecl.Body.List
+	insertionIndex := len(body)
+	switch target.Anchor {
+	case AnchorStart:
+		insertionIndex = 0
+	case AnchorEnd:
+	case AnchorBefore:
+		for i, stmt := range body {
+			if r.matches(target, stmt) {
+				insertionIndex = i
+				break
 			}
 		}
-
-		if insertionIndex == -1 {
-			insertionIndex = len(funcDecl.Body.List)
+	case AnchorAfter:
+		for i, stmt := range body {
+			if r.matches(target, stmt) {
+				insertionIndex = i + 1
+			}
 		}
+	default:
+		return false, fmt.Errorf("unknown anchor %q, expected one of %s, %s, %s or %s",
+			target.Anchor, AnchorStart, AnchorEnd, AnchorBefore, AnchorAfter)
+	}
 
-		originalStmts := funcDecl.Body.List
-		var newStmts []ast.Stmt
-		for _, code := range codes {
-			stmt, err := r.parseStmt(code)
-			if err != nil {
-				log.Fatalf("Failed to parse statement: %v %v", code, err)
-				parseErr = err
-				return false
-			}
-			newStmts = append(newStmts, stmt)
+	return r.insertStmts(funcDecl.Body, insertionIndex, codes)
+}
+
+// matches reports whether stmt is an anchor statement of target.
+func (r *astParser) matches(target Target, stmt ast.Stmt) bool {
+	if target.Match != "" {
+		code := r.src[r.offset(stmt.Pos()):r.offset(stmt.End())]
+		return bytes.Contains(code, []byte(target.Match))
+	}
+	if target.Receiver == "" {
+		return false
+	}
+	switch stmt := stmt.(type) {
+	case *ast.ExprStmt:
+		return callReceiver(stmt.X) == target.Receiver
+	case *ast.AssignStmt:
+		return stmt.Tok == token.DEFINE && slices.ContainsFunc(stmt.Lhs, func(lhs ast.Expr) bool {
+			ident, ok := lhs.(*ast.Ident)
+			return ok && ident.Name == target.Receiver
+		})
+	}
+	return false
+}
+
+// callReceiver returns the variable a chain of method calls starts from,
+// e.g. router for router.Group("/api").GET("/", handler).
+func callReceiver(expr ast.Expr) string {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	selExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	switch x := selExpr.X.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.CallExpr:
+		return callReceiver(x)
+	}
+	return ""
+}
+
+// insertStmts inserts the statements in codes that body does not have yet
+// before the statement at index, keeping the comments around it in place,
+// and reports whether it inserted any.
+func (r *astParser) insertStmts(body *ast.BlockStmt, index int, codes []string) (bool, error) {
+	indent := r.stmtIndent(body, index)
+	var text string
+	for _, code := range codes {
+		stmt, err := parseStmt(code)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse statement %q: %w", code, err)
+		}
+		if r.hasStmt(body, stmt) {
+			continue
 		}
+		text += "\n" + indent + code
+	}
+	if text == "" {
+		return false, nil
+	}
 
-		// check if the code is already in the list
-		var cleanNewStmts []ast.Stmt
-		for _, newStmt := range newStmts {
-			var isExists = false
-			for _, stmt := range originalStmts {
-				isExists = r.isStmtExists(stmt, newStmt)
-				if isExists {
-					break
-				}
-			}
-			if !isExists {
-				cleanNewStmts = append(cleanNewStmts, newStmt)
-			}
+	// the statements go at the end of the line of the previous statement,
+	// after its trailing comment, or on the line after the opening brace
+	after := r.offset(body.Lbrace) + 1
+	if index > 0 {
+		after = r.offset(body.List[index-1].End())
+	}
+	next := r.offset(body.Rbrace)
+	if index < len(body.List) {
+		next = r.offset(body.List[index].Pos())
+	}
+	offset := r.lineEnd(after)
+	if next < offset {
+		// the next statement or the closing brace is on the same line, as
+		// in func() { a() }
+		offset = after
+		text += "\n"
+	}
+
+	if err := r.insert(offset, text); err != nil {
+		return false, fmt.Errorf("failed to insert statements in %s: %w", r.filePath, err)
+	}
+	return true, nil
+}
+
+// hasStmt reports whether body already has a statement equal to stmt.
+func (r *astParser) hasStmt(body *ast.BlockStmt, stmt ast.Stmt) bool {
+	for _, existing := range body.List {
+		if isStmtExists(existing, stmt) {
+			return true
 		}
+	}
+	return false
+}
 
-		funcDecl.Body.List = append(
-			originalStmts[:insertionIndex],
-			append(cleanNewStmts, originalStmts[insertionIndex:]...)...)
+// isStmtExists reports whether stmt and code are the same statement,
+// ignoring formatting and comments.
+func isStmtExists(stmt ast.Stmt, code ast.Stmt) bool {
+	return isNodeEqual(stmt, code)
+}
 
-		return false // Stop searching
-	})
-	return parseErr
+// isNodeEqual reports whether a and b print the same, ignoring formatting
+// and comments.
+func isNodeEqual(a ast.Node, b ast.Node) bool {
+	aString, err := nodeToString(a)
+	if err != nil {
+		return false
+	}
+	bString, err := nodeToString(b)
+	if err != nil {
+		return false
+	}
+	return aString == bString
 }
 
-// ParseStmt takes a string containing a single Go statement and returns the corresponding AST node.
-// It now also strips all position information from the new node, which is CRITICAL
-// for preventing the go/printer from mis-formatting it upon insertion.
-func (r *astParser) parseStmt(code string) (ast.Stmt, error) {
-	src := fmt.Sprintf("package p\n\nfunc f() { %s }", code)
+// parseStmt parses a string containing a single Go statement.
+func parseStmt(code string) (ast.Stmt, error) {
+	src := fmt.Sprintf("package p\n\nfunc f() {\n%s\n}", code)
 	file, err := parser.ParseFile(token.NewFileSet(), "src.go", src, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse code string: %w", err)
 	}
 
 	fn, ok := file.Decls[0].(*ast.FuncDecl)
-	if !ok || len(fn.Body.List) == 0 {
-		return nil, fmt.Errorf("failed to find statement in parsed code")
-	}
-
-	// Get the statement we care about
-	stmt := fn.Body.List[0]
-
-	// *** THE CRITICAL FIX IS HERE ***
-	// Walk the new AST snippet and remove all position info.
-	// This tells the printer to format it based on its structure, not its
-	// (now meaningless) original position.
-	ast.Inspect(stmt, func(n ast.Node) bool {
-		if n != nil {
-			// A slightly more robust way to do this, which works
-			// for different node types.
-			switch node := n.(type) {
-			case *ast.AssignStmt:
-				node.TokPos = token.NoPos
-			case *ast.CallExpr:
-				node.Lparen = token.NoPos
-				node.Rparen = token.NoPos
-			case *ast.SelectorExpr:
-				// No Pos field to reset directly here, it's composed of X and Sel
-			case *ast.Ident:
-				node.NamePos = token.NoPos
-			}
-		}
-		return true
-	})
-
-	return stmt, nil
+	if !ok || len(fn.Body.List) != 1 {
+		return nil, fmt.Errorf("expected a single statement")
+	}
+	return fn.Body.List[0], nil
 }
 
-func (r *astParser) nodeToString(n ast.Node) (string, error) {
+// nodeToString prints n without position information, which gives a
+// canonical string to compare statements with.
+func nodeToString(n ast.Node) (string, error) {
 	var buf bytes.Buffer
-	// Use a new, empty fileset to erase all position info.
-	// This is the key to getting a canonical, comparable string.
-	fset := token.NewFileSet()
-	err := printer.Fprint(&buf, fset, n)
+	err := printer.Fprint(&buf, token.NewFileSet(), n)
 	if err != nil {
 		return "", err
 	}
 	return buf.String(), nil
 }
-func (r *astParser) isStmtExists(stmt ast.Stmt, code ast.Stmt) bool {
-	stmtString, err := r.nodeToString(stmt)
-	if err != nil {
-		return false
-	}
-	codeString, err := r.nodeToString(code)
-	if err != nil {
-		return false
-	}
-	return stmtString == codeString
-}
@@ -1,30 +1,1010 @@
 package parser
 
 import (
+	"os"
+	"strings"
 	"testing"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"gopkg.in/go-playground/assert.v1"
 )
 
+// routerTail is the part of the fixture after Routes, which no test edits.
+const routerTail = `
+type Router interface {
+	GET(path string, handler func())
+}
+
+type router struct {
+}
+
+func NewRouter() Router {
+	return &router{}
+}
+
+func (r *router) GET(path string, handler func()) {
+	handler()
+}
+`
+
+// loadRouter copies the router fixture into an in-memory filesystem so the
+// tests never rewrite the fixture itself.
+func loadRouter(t *testing.T) file.File {
+	t.Helper()
+	src, err := os.ReadFile("router_dummy.go")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	files := file.New(file.NewMemFS())
+	if err := files.CreateFile("router_dummy.go", src); err != nil {
+		t.Fatalf("Failed to copy fixture: %v", err)
+	}
+	return files
+}
+
+// loadSource writes src into an in-memory filesystem and parses it.
+func loadSource(t *testing.T, src string) (ASTParser, file.File) {
+	t.Helper()
+	files := file.New(file.NewMemFS())
+	if err := files.CreateFile("router_dummy.go", []byte(src)); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	parser, err := NewASTParser(files, "router_dummy.go")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	return parser, files
+}
+
+// writeAndRead writes the parser's file and returns its new content.
+func writeAndRead(t *testing.T, parser ASTParser, files file.File) string {
+	t.Helper()
+	if err := parser.WriteFile(); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	content, err := files.ReadFile("router_dummy.go")
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return string(content)
+}
+
 func TestRouter(t *testing.T) {
-	filePath := "router_dummy.go"
-	parser := NewASTParser(filePath)
+	files := loadRouter(t)
+	parser, err := NewASTParser(files, "router_dummy.go")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
 	parser.AddImport("github.com/IrwantoCia/gomakase/internal/auth/application", "authApp")
-	parser.WriteFile()
+	parser.AddImport("fmt", "")
+	content := writeAndRead(t, parser, files)
+	assert.Equal(t, content, `package parser
+
+import (
+	"fmt"
+
+	authApp "github.com/IrwantoCia/gomakase/internal/auth/application"
+)
+
+// Routes registers the routes of the application.
+func Routes() {
+	// the router every route is registered on
+	var router = NewRouter()
+	_ = "bar"
+
+	router.GET("/", func() { fmt.Println("Hello, World!") }) // home page
+
+	// keep this comment at the end
+}
+`+routerTail)
 }
 
 func TestAddDependencies(t *testing.T) {
-	filePath := "router_dummy.go"
-	parser := NewASTParser(filePath)
-	code := `_ = "bar"`
-	err := parser.AddDependencies([]string{code})
+	files := loadRouter(t)
+	parser, err := NewASTParser(files, "router_dummy.go")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	err = parser.AddDependencies([]string{`_ = "bar"`, `repository := NewRepository()`, `service := NewService(repository)`})
 	if err != nil {
 		t.Fatalf("Failed to parse statement: %v", err)
 	}
-	parser.WriteFile()
+	content := writeAndRead(t, parser, files)
+	assert.Equal(t, content, `package parser
+
+import (
+	"fmt"
+)
+
+// Routes registers the routes of the application.
+func Routes() {
+	// the router every route is registered on
+	var router = NewRouter()
+	_ = "bar"
+	repository := NewRepository()
+	service := NewService(repository)
+
+	router.GET("/", func() { fmt.Println("Hello, World!") }) // home page
+
+	// keep this comment at the end
+}
+`+routerTail)
 }
 
 func TestAddRouter(t *testing.T) {
-	filePath := "router_dummy.go"
-	parser := NewASTParser(filePath)
-	parser.AddRoute("router.GET(\"/login\", authHandler.LoginPage)")
-	parser.WriteFile()
+	files := loadRouter(t)
+	parser, err := NewASTParser(files, "router_dummy.go")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		err = parser.AddRoute("router.GET(\"/login\", authHandler.LoginPage)")
+		if err != nil {
+			t.Fatalf("Failed to add route: %v", err)
+		}
+	}
+	content := writeAndRead(t, parser, files)
+	assert.Equal(t, content, `package parser
+
+import (
+	"fmt"
+)
+
+// Routes registers the routes of the application.
+func Routes() {
+	// the router every route is registered on
+	var router = NewRouter()
+	_ = "bar"
+
+	router.GET("/", func() { fmt.Println("Hello, World!") }) // home page
+	router.GET("/login", authHandler.LoginPage)
+
+	// keep this comment at the end
+}
+`+routerTail)
+}
+
+func TestAddImport_NoImports(t *testing.T) {
+	parser, files := loadSource(t, "// Package main is the server.\npackage main\n\n// Routes is empty.\nfunc Routes() {}\n")
+	parser.AddImport("fmt", "")
+	parser.AddImport("os", "")
+	content := writeAndRead(t, parser, files)
+	assert.Equal(t, content, `// Package main is the server.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Routes is empty.
+func Routes() {}
+`)
+}
+
+func TestAddRoute_OneLineBody(t *testing.T) {
+	parser, files := loadSource(t, "package main\n\nfunc Routes() { router.GET(\"/\", nil) }\n")
+	if err := parser.AddRoute(`router.POST("/", nil)`); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if err := parser.AddDependencies([]string{`handler := NewHandler()`}); err != nil {
+		t.Fatalf("Failed to add dependency: %v", err)
+	}
+	content := writeAndRead(t, parser, files)
+	assert.Equal(t, content, `package main
+
+func Routes() {
+	handler := NewHandler()
+	router.GET("/", nil)
+	router.POST("/", nil)
+}
+`)
+}
+
+func TestAddDependencies_Invalid(t *testing.T) {
+	files := loadRouter(t)
+	parser, err := NewASTParser(files, "router_dummy.go")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if err := parser.AddDependencies([]string{`a := `}); err == nil {
+		t.Fatalf("Expected an error for an invalid statement")
+	}
+	src, _ := os.ReadFile("router_dummy.go")
+	assert.Equal(t, writeAndRead(t, parser, files), string(src))
+}
+
+func TestCheckRoutes(t *testing.T) {
+	files := file.New(file.NewMemFS())
+	src := "package main\n\nfunc Router() {}\n"
+	if err := files.CreateFile("router.go", []byte(src)); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	parser, err := NewASTParser(files, "router.go")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if err := parser.CheckRoutes(); err == nil {
+		t.Fatalf("Expected an error for a file without %s", RoutesFunc)
+	}
+	if err := parser.AddRoute("router.GET(\"/\", nil)"); err == nil {
+		t.Fatalf("Expected AddRoute to fail without %s", RoutesFunc)
+	}
+	if err := parser.AddDependencies([]string{`_ = "bar"`}); err == nil {
+		t.Fatalf("Expected AddDependencies to fail without %s", RoutesFunc)
+	}
+}
+
+func TestAddRoute_ChainedCall(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+func Routes() {
+	router.Use(logger)
+	router.Group("/api").GET("/users", listUsers)
+}
+`)
+	if err := parser.AddRoute(`router.Group("/api").POST("/users", createUser)`); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if err := parser.AddDependencies([]string{`logger := NewLogger()`}); err != nil {
+		t.Fatalf("Failed to add dependency: %v", err)
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+func Routes() {
+	logger := NewLogger()
+	router.Use(logger)
+	router.Group("/api").GET("/users", listUsers)
+	router.Group("/api").POST("/users", createUser)
+}
+`)
+}
+
+func TestAddStatements(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+type server struct{}
+
+// Setup wires the server.
+func (s *server) Setup() {
+	db := Connect()
+	// migrate the schema before serving
+	db.Migrate()
+	s.Serve(db)
+}
+`)
+	targets := []struct {
+		target Target
+		code   string
+	}{
+		{Target{Function: "server.Setup", Anchor: AnchorStart}, `config := Load()`},
+		{Target{Function: "server.Setup", Anchor: AnchorEnd}, `s.Close()`},
+		{Target{Function: "server.Setup", Anchor: AnchorAfter, Match: "Connect()"}, `db.Ping()`},
+		{Target{Function: "server.Setup", Anchor: AnchorBefore, Receiver: "s"}, `s.Log(db)`},
+	}
+	for _, tt := range targets {
+		if _, err := parser.AddStatements(tt.target, []string{tt.code}); err != nil {
+			t.Fatalf("Failed to add %s: %v", tt.code, err)
+		}
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+type server struct{}
+
+// Setup wires the server.
+func (s *server) Setup() {
+	config := Load()
+	db := Connect()
+	db.Ping()
+	// migrate the schema before serving
+	db.Migrate()
+	s.Log(db)
+	s.Serve(db)
+	s.Close()
+}
+`)
+}
+
+func TestAddStatements_Indent(t *testing.T) {
+	parser, _ := loadSource(t, `package main
+
+type server struct{}
+
+func (s *server) Setup() {
+    db := Connect()
+    s.Serve(db)
+}
+
+func (s *server) Stop() {
+}
+`)
+	targets := []struct {
+		target Target
+		code   string
+	}{
+		{Target{Function: "server.Setup", Anchor: AnchorEnd}, `s.Close()`},
+		{Target{Function: "server.Stop", Anchor: AnchorEnd}, `s.Close()`},
+	}
+	for _, tt := range targets {
+		if _, err := parser.AddStatements(tt.target, []string{tt.code}); err != nil {
+			t.Fatalf("Failed to add %s: %v", tt.code, err)
+		}
+	}
+	// the statements are indented like the ones around them before the
+	// file is formatted
+	src := string(parser.(*astParser).src)
+	for _, code := range []string{"    s.Serve(db)\n    s.Close()\n}", "func (s *server) Stop() {\n\ts.Close()\n}"} {
+		if !strings.Contains(src, code) {
+			t.Fatalf("Expected %q in:\n%s", code, src)
+		}
+	}
+}
+
+func TestAddStatements_InvalidTarget(t *testing.T) {
+	parser, _ := loadSource(t, "package main\n\nfunc main() {}\n")
+	if _, err := parser.AddStatements(Target{Function: "main", Anchor: "middle"}, []string{`_ = 1`}); err == nil {
+		t.Fatalf("Expected an error for an unknown anchor")
+	}
+	if _, err := parser.AddStatements(Target{Function: "server.main", Anchor: AnchorEnd}, []string{`_ = 1`}); err == nil {
+		t.Fatalf("Expected an error for a missing method")
+	}
+}
+
+func TestAddRouteGroup(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+func Routes() {
+	router.GET("/", home)
+	router.NoRoute(notFound)
+}
+`)
+	group := RouteGroup{Name: "authorized", Prefix: "/", Middlewares: []string{"middleware.AuthMiddleware(&authService)"}}
+	for i := 0; i < 2; i++ {
+		name, groupAdded, err := parser.AddRouteGroup(RouteTarget, group)
+		if err != nil {
+			t.Fatalf("Failed to add group: %v", err)
+		}
+		assert.Equal(t, name, "authorized")
+		groupTarget := Target{Function: RoutesFunc, Receiver: name, Anchor: AnchorAfter}
+		routeAdded, err := parser.AddStatements(groupTarget, []string{`authorized.POST("/logout", logout)`})
+		if err != nil {
+			t.Fatalf("Failed to add route: %v", err)
+		}
+		// only the first run inserts anything
+		assert.Equal(t, groupAdded, i == 0)
+		assert.Equal(t, routeAdded, i == 0)
+	}
+	if err := parser.AddRoute(`router.GET("/about", about)`); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+func Routes() {
+	router.GET("/", home)
+	router.NoRoute(notFound)
+	router.GET("/about", about)
+	authorized := router.Group("/", middleware.AuthMiddleware(&authService))
+	authorized.POST("/logout", logout)
+}
+`)
+
+	if _, _, err := parser.AddRouteGroup(RouteTarget, RouteGroup{Name: "authorized", Prefix: "/admin"}); err == nil {
+		t.Fatalf("Expected an error for a variable that is already declared")
+	}
+}
+
+func TestMoveRoutes(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+func Routes() {
+	router.GET("/", home)
+	// admin pages
+	router.GET("/admin", dashboard)
+	router.POST("/admin/users", createUser) // creates a user
+	router.GET("/administrator", other)
+	router.Group("/admin").GET("/stats", stats)
+}
+`)
+	name, _, err := parser.AddRouteGroup(RouteTarget, RouteGroup{Name: "adminGroup", Prefix: "/admin", Middlewares: []string{"auth"}})
+	if err != nil {
+		t.Fatalf("Failed to add group: %v", err)
+	}
+	moved, err := parser.MoveRoutes(RouteTarget, "/admin", name)
+	if err != nil {
+		t.Fatalf("Failed to move routes: %v", err)
+	}
+	assert.Equal(t, moved, []string{`router.GET("/admin", dashboard)`, `router.POST("/admin/users", createUser)`})
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+func Routes() {
+	router.GET("/", home)
+	// admin pages
+	router.GET("/administrator", other)
+	router.Group("/admin").GET("/stats", stats)
+	adminGroup := router.Group("/admin", auth)
+	adminGroup.GET("", dashboard)
+	adminGroup.POST("/users", createUser) // creates a user
+}
+`)
+
+	moved, err = parser.MoveRoutes(RouteTarget, "/admin", name)
+	if err != nil {
+		t.Fatalf("Failed to move routes: %v", err)
+	}
+	assert.Equal(t, len(moved), 0)
+	group, ok := parser.FindRouteGroup(RouteTarget, "/admin")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, group, RouteGroup{Name: "adminGroup", Prefix: "/admin", Middlewares: []string{"auth"}})
+	_, ok = parser.FindRouteGroup(RouteTarget, "/api")
+	assert.Equal(t, ok, false)
+}
+
+func TestRemove(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+import (
+	"net/http"
+
+	authApp "example.com/demo/internal/auth/application"
+	authDelivery "example.com/demo/internal/auth/delivery"
+	"example.com/demo/internal/shared/middleware"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var _ = jwt.New
+
+// Routes registers the routes.
+func Routes() {
+	authService := authApp.NewAuthService()
+	authHandler := authDelivery.NewAuthHandler(&authService) // handles login
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/login", authHandler.LoginPage)
+	router.Group("/api").GET("/login", authHandler.LoginPage)
+	authorized := router.Group("/", middleware.AuthMiddleware(&authService))
+	authorized.POST("/logout", authHandler.Logout) // ends the session
+}
+`)
+	for _, route := range [][2]string{{"GET", "/login"}, {"GET", "/api/login"}, {"POST", "/logout"}} {
+		removed, err := parser.RemoveRoute(route[0], route[1])
+		if err != nil {
+			t.Fatalf("Failed to remove route: %v", err)
+		}
+		assert.Equal(t, removed, true)
+	}
+	for _, dependency := range []string{"authHandler", "authorized := router.Group(\"/\",  middleware.AuthMiddleware(&authService))", "authService"} {
+		removed, err := parser.RemoveDependency(dependency)
+		if err != nil {
+			t.Fatalf("Failed to remove dependency: %v", err)
+		}
+		assert.Equal(t, removed, true)
+	}
+	removed, err := parser.RemoveRoute("GET", "/missing")
+	if err != nil {
+		t.Fatalf("Failed to remove route: %v", err)
+	}
+	assert.Equal(t, removed, false)
+
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var _ = jwt.New
+
+// Routes registers the routes.
+func Routes() {
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+}
+`)
+}
+
+func TestRemove_ImportNames(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+import (
+	"example.com/demo/internal/go-util"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Routes registers the routes.
+func Routes() {
+	pod := v1.Pod{}
+	meta := metav1.ObjectMeta{}
+	helper := util.New()
+	router.GET("/", nil)
+}
+`)
+	// the package of core/v1 is v1, which the file still uses, and the name
+	// of go-util cannot be told from its path
+	for _, dependency := range []string{"meta", "helper"} {
+		if _, err := parser.RemoveDependency(dependency); err != nil {
+			t.Fatalf("Failed to remove dependency: %v", err)
+		}
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+import (
+	"example.com/demo/internal/go-util"
+	"k8s.io/api/core/v1"
+)
+
+// Routes registers the routes.
+func Routes() {
+	pod := v1.Pod{}
+	router.GET("/", nil)
+}
+`)
+
+	if _, err := parser.RemoveDependency("pod"); err != nil {
+		t.Fatalf("Failed to remove dependency: %v", err)
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+import (
+	"example.com/demo/internal/go-util"
+)
+
+// Routes registers the routes.
+func Routes() {
+	router.GET("/", nil)
+}
+`)
+}
+
+func TestRemoveImport(t *testing.T) {
+	parser, files := loadSource(t, "package main\n\nimport \"fmt\"\n\n// Routes is empty.\nfunc Routes() {}\n")
+	removed, err := parser.RemoveImport("fmt")
+	if err != nil {
+		t.Fatalf("Failed to remove import: %v", err)
+	}
+	assert.Equal(t, removed, true)
+	removed, _ = parser.RemoveImport("os")
+	assert.Equal(t, removed, false)
+	assert.Equal(t, writeAndRead(t, parser, files), "package main\n\n// Routes is empty.\nfunc Routes() {}\n")
+}
+
+func TestAddImport_Groups(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+import (
+	"net/http"
+
+	"shop/internal/shared/db"
+	// the logger of every request
+	"shop/internal/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(router *gin.Engine, database db.Database, logger logger.Logger) {
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+}
+`)
+	for _, importPath := range []string{
+		"shop/internal/user/application",
+		"time",
+		"net/url",
+		"github.com/gin-contrib/cors",
+		"example.com/payments",
+	} {
+		if _, _, err := parser.AddImport(importPath, ""); err != nil {
+			t.Fatalf("Failed to import %s: %v", importPath, err)
+		}
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"shop/internal/shared/db"
+	// the logger of every request
+	"shop/internal/shared/logger"
+	"shop/internal/user/application"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"example.com/payments"
+)
+
+func Routes(router *gin.Engine, database db.Database, logger logger.Logger) {
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+}
+`)
+
+	// a standard import starts a group of its own before a single import
+	// of another module
+	parser, files = loadSource(t, "package main\n\nimport \"github.com/gin-gonic/gin\"\n\nvar _ gin.H\n")
+	if _, _, err := parser.AddImport("time", ""); err != nil {
+		t.Fatalf("Failed to import time: %v", err)
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ gin.H
+`)
+}
+
+func TestAddImport_Collision(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+import (
+	authApp "example.com/other/auth"
+	cfg "example.com/demo/config"
+)
+
+func middleware() {}
+
+func Routes() {
+	authApp.Use(cfg.Config)
+}
+`)
+	imports := []struct {
+		path  string
+		alias string
+		name  string
+		added bool
+	}{
+		{"example.com/demo/auth", "authApp", "authApp2", true},
+		{"example.com/demo/config", "config", "cfg", false},
+		{"example.com/demo/middleware", "", "middleware2", true},
+		{"example.com/demo/auth", "authApp", "authApp2", false},
+		{"github.com/golang-jwt/jwt/v5", "", "jwt", true},
+	}
+	for _, tt := range imports {
+		name, added, err := parser.AddImport(tt.path, tt.alias)
+		if err != nil {
+			t.Fatalf("Failed to import %s: %v", tt.path, err)
+		}
+		assert.Equal(t, name, tt.name)
+		assert.Equal(t, added, tt.added)
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+import (
+	authApp2 "example.com/demo/auth"
+	cfg "example.com/demo/config"
+	middleware2 "example.com/demo/middleware"
+	authApp "example.com/other/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func middleware() {}
+
+func Routes() {
+	authApp.Use(cfg.Config)
+}
+`)
+}
+
+func TestAddMigration(t *testing.T) {
+	parser, files := loadSource(t, `package main
+
+// Schemas are migrated on start.
+func Schemas() []interface{} {
+	return []interface{}{}
+}
+`)
+	schemas := []struct {
+		path   string
+		alias  string
+		schema string
+		code   string
+		added  bool
+	}{
+		{"example.com/demo/internal/auth/infrastructure", "authInfra", "UserSchema", "&authInfra.UserSchema{}", true},
+		{"example.com/demo/internal/auth/infrastructure", "authInfra", "AuthSchema", "&authInfra.AuthSchema{}", true},
+		{"example.com/demo/internal/user/infrastructure", "", "UserSchema", "&infrastructure.UserSchema{}", true},
+		{"example.com/demo/internal/auth/infrastructure", "authInfra", "UserSchema", "&authInfra.UserSchema{}", false},
+	}
+	for _, tt := range schemas {
+		code, added, err := parser.AddMigration(tt.path, tt.alias, tt.schema)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", tt.schema, err)
+		}
+		assert.Equal(t, code, tt.code)
+		assert.Equal(t, added, tt.added)
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package main
+
+import (
+	authInfra "example.com/demo/internal/auth/infrastructure"
+	"example.com/demo/internal/user/infrastructure"
+)
+
+// Schemas are migrated on start.
+func Schemas() []interface{} {
+	return []interface{}{
+		&authInfra.UserSchema{},
+		&authInfra.AuthSchema{},
+		&infrastructure.UserSchema{},
+	}
+}
+`)
+
+	parser, _ = loadSource(t, "package main\n\nfunc Schemas() []interface{} {\n\treturn nil\n}\n")
+	if _, _, err := parser.AddMigration("example.com/demo/internal/user/infrastructure", "", "UserSchema"); err == nil {
+		t.Error("Expected an error without a list of schemas")
+	}
+}
+
+func TestWriteFile_Invalid(t *testing.T) {
+	src := `package main
+
+func Routes() {
+	userService := NewUserService()
+	router.GET("/users", userService.List)
+	// an error the file already has is not the edits' fault
+	var count int = "none"
+}
+`
+	parser, files := loadSource(t, src)
+	if err := parser.AddDependencies([]string{"userService := NewOtherService()"}); err != nil {
+		t.Fatalf("Failed to add dependency: %v", err)
+	}
+	err := parser.WriteFile()
+	if err == nil || !strings.Contains(err.Error(), "no new variables") {
+		t.Fatalf("Expected the redeclared variable to be reported, got %v", err)
+	}
+	content, _ := files.ReadFile("router_dummy.go")
+	assert.Equal(t, string(content), src)
+
+	parser, files = loadSource(t, src)
+	if err := parser.AddRoute(`router.GET("/users/:id", userService.Get)`); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if !strings.Contains(writeAndRead(t, parser, files), `router.GET("/users/:id", userService.Get)`) {
+		t.Fatal("Expected the route to be written")
+	}
+}
+
+func TestAddConfig(t *testing.T) {
+	parser, files := loadSource(t, `package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+type AppConfig struct {
+	LogLevel string `+"`mapstructure:\"LOG_LEVEL\"`"+`
+}
+
+type MailConfig struct {
+	Host string `+"`mapstructure:\"SMTP_HOST\"`"+`
+}
+
+func Load() error {
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("MAIL.SMTP_HOST", "localhost")
+
+	viper.AutomaticEnv()
+	return fmt.Errorf("not loaded")
+}
+`)
+	section := ConfigSection{Name: "Mail", Settings: []ConfigSetting{
+		{Name: "Host", Type: "string", Key: "SMTP_HOST", Default: "localhost"},
+		{Name: "Port", Type: "int", Key: "SMTP_PORT", Default: "587", Description: "Port of the SMTP server"},
+		{Name: "Timeout", Type: "time.Duration", Key: "SMTP_TIMEOUT", Default: "10s"},
+	}}
+	keys, err := parser.AddConfig(section)
+	if err != nil {
+		t.Fatalf("Failed to add config: %v", err)
+	}
+	assert.Equal(t, keys, []string{"MAIL.SMTP_PORT", "MAIL.SMTP_TIMEOUT"})
+
+	// a second time there is nothing to add
+	keys, err = parser.AddConfig(section)
+	if err != nil {
+		t.Fatalf("Failed to add config: %v", err)
+	}
+	assert.Equal(t, keys, []string{})
+
+	assert.Equal(t, writeAndRead(t, parser, files), `package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type AppConfig struct {
+	LogLevel string     `+"`mapstructure:\"LOG_LEVEL\"`"+`
+	Mail     MailConfig `+"`mapstructure:\"MAIL\"`"+`
+}
+
+type MailConfig struct {
+	Host string `+"`mapstructure:\"SMTP_HOST\"`"+`
+	// Port of the SMTP server
+	Port    int           `+"`mapstructure:\"SMTP_PORT\"`"+`
+	Timeout time.Duration `+"`mapstructure:\"SMTP_TIMEOUT\"`"+`
+}
+
+func Load() error {
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("MAIL.SMTP_HOST", "localhost")
+
+	viper.SetDefault("MAIL.SMTP_PORT", 587)
+	viper.SetDefault("MAIL.SMTP_TIMEOUT", "10s")
+
+	viper.AutomaticEnv()
+	return fmt.Errorf("not loaded")
+}
+`)
+}
+
+func TestAddConfig_QualifiedTypes(t *testing.T) {
+	parser, files := loadSource(t, `package config
+
+import (
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// time is taken, so the time package is imported as time2
+func time() string { return "now" }
+
+type AppConfig struct{}
+
+func Load() {
+	viper.SetConfigFile(os.Getenv("CONFIG"))
+	viper.AutomaticEnv()
+}
+`)
+	section := ConfigSection{Name: "Cache", Settings: []ConfigSetting{
+		{Name: "TTLs", Type: "map[string]time.Duration", Key: "TTLS", Default: "users=1h"},
+		{Name: "Now", Type: "func() time.Time", Key: "NOW"},
+		{Name: "Zones", Type: "[]*time.Location", Key: "ZONES"},
+	}}
+	if _, err := parser.AddConfig(section); err != nil {
+		t.Fatalf("Failed to add config: %v", err)
+	}
+	assert.Equal(t, writeAndRead(t, parser, files), `package config
+
+import (
+	"os"
+	time2 "time"
+
+	"github.com/spf13/viper"
+)
+
+// time is taken, so the time package is imported as time2
+func time() string { return "now" }
+
+type AppConfig struct {
+	Cache CacheConfig `+"`mapstructure:\"CACHE\"`"+`
+}
+
+type CacheConfig struct {
+	TTLs  map[string]time2.Duration `+"`mapstructure:\"TTLS\"`"+`
+	Now   func() time2.Time         `+"`mapstructure:\"NOW\"`"+`
+	Zones []*time2.Location         `+"`mapstructure:\"ZONES\"`"+`
+}
+
+func Load() {
+	viper.SetConfigFile(os.Getenv("CONFIG"))
+
+	viper.SetDefault("CACHE.TTLS", "users=1h")
+	viper.SetDefault("CACHE.NOW", "")
+	viper.SetDefault("CACHE.ZONES", "")
+	viper.AutomaticEnv()
+}
+`)
+
+	if _, err := parser.AddConfig(ConfigSection{Name: "Bad", Settings: []ConfigSetting{{Name: "Size", Type: "map[string", Key: "SIZE"}}}); err == nil {
+		t.Fatal("Expected an error for an invalid type")
+	}
+}
+
+func TestListRoutes(t *testing.T) {
+	files := file.New(file.NewMemFS())
+	sources := map[string]string{
+		RouterFile: `package main
+
+import (
+	orderDelivery "demo/internal/order/delivery"
+
+	"demo/internal/shared/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(router *gin.Engine, logger Logger) {
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{})
+	})
+	api := router.Group("/api", middleware.Logger(logger))
+	{
+		api.POST("/login", middleware.RateLimit(), authHandler.Login)
+	}
+	adminGroup := api.Group("/admin")
+	adminGroup.Use(middleware.AuthMiddleware(&authService))
+	adminGroup.DELETE("/users/:id", userHandler.Delete)
+	router.Group("/v1").Handle("PUT", "/items", itemHandler.Put)
+
+	adminRoutes(adminGroup)
+	orderDelivery.RegisterRoutes(api)
+	orderHandler := orderDelivery.NewOrderHandler(logger)
+	orderHandler.Routes(router)
+}
+`,
+		"cmd/server/admin.go": `package main
+
+func adminRoutes(group *gin.RouterGroup) {
+	group.GET("/stats", statsHandler.Get)
+	adminRoutes(group)
+}
+`,
+		"internal/order/delivery/routes.go": `package delivery
+
+func RegisterRoutes(r gin.IRouter) {
+	r.GET("/orders", listOrders)
+}
+
+func (h *OrderHandler) Routes(router *gin.Engine) {
+	router.GET("/orders/:id", h.Get)
+}
+`,
+	}
+	for path, src := range sources {
+		if err := files.CreateFile(path, []byte(src)); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	routes, err := ListRoutes(files, "demo")
+	if err != nil {
+		t.Fatalf("Failed to list routes: %v", err)
+	}
+	assert.Equal(t, routes, []Route{
+		{Method: "GET", Path: "/health", Handler: "func(c *gin.Context) {...}", Middlewares: []string{}, File: RouterFile, Line: 11},
+		{Method: "POST", Path: "/api/login", Handler: "authHandler.Login", Middlewares: []string{"middleware.Logger(logger)", "middleware.RateLimit()"}, Group: "api", File: RouterFile, Line: 16},
+		{Method: "DELETE", Path: "/api/admin/users/:id", Handler: "userHandler.Delete", Middlewares: []string{"middleware.Logger(logger)", "middleware.AuthMiddleware(&authService)"}, Group: "adminGroup", File: RouterFile, Line: 20},
+		{Method: "PUT", Path: "/v1/items", Handler: "itemHandler.Put", Middlewares: []string{}, Group: `router.Group("/v1")`, File: RouterFile, Line: 21},
+		{Method: "GET", Path: "/api/admin/stats", Handler: "statsHandler.Get", Middlewares: []string{"middleware.Logger(logger)", "middleware.AuthMiddleware(&authService)"}, Group: "adminGroup", File: "cmd/server/admin.go", Line: 4},
+		{Method: "GET", Path: "/api/orders", Handler: "listOrders", Middlewares: []string{"middleware.Logger(logger)"}, Group: "api", File: "internal/order/delivery/routes.go", Line: 4},
+		{Method: "GET", Path: "/orders/:id", Handler: "h.Get", Middlewares: []string{}, File: "internal/order/delivery/routes.go", Line: 8},
+	})
+}
+
+func TestListRoutes_Invalid(t *testing.T) {
+	files := file.New(file.NewMemFS())
+	sources := map[string]string{
+		RouterFile: `package main
+
+import orderDelivery "demo/internal/order/delivery"
+
+func Routes(router *gin.Engine) {
+	router.GET("/health", health)
+	orderDelivery.RegisterRoutes(router)
+}
+`,
+		"internal/order/delivery/routes.go": "package delivery\n\nfunc RegisterRoutes(r gin.IRouter) {\n\tr.GET(\"/orders\", listOrders\n}\n",
+	}
+	for path, src := range sources {
+		if err := files.CreateFile(path, []byte(src)); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	// the routes of a file that does not parse are not silently left out
+	_, err := ListRoutes(files, "demo")
+	if err == nil || !strings.Contains(err.Error(), "internal/order/delivery/routes.go") {
+		t.Fatalf("Expected an error naming the route file, got %v", err)
+	}
 }
@@ -0,0 +1,292 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/format"
+)
+
+// RemoveImport removes the import of importPath, with any alias, and
+// reports whether the file had it.
+func (r *astParser) RemoveImport(importPath string) (bool, error) {
+	ranges := [][2]int{}
+	for _, decl := range r.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		removed := 0
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			if importSpec.Path.Value == strconv.Quote(importPath) {
+				ranges = append(ranges, r.stmtRange(importSpec))
+				removed++
+			}
+		}
+		// drop the declaration with its last import
+		if removed > 0 && removed == len(genDecl.Specs) {
+			ranges = ranges[:len(ranges)-removed]
+			ranges = append(ranges, [2]int{r.offset(genDecl.Pos()), r.offset(genDecl.End())})
+		}
+	}
+	if len(ranges) == 0 {
+		return false, nil
+	}
+	return true, r.remove(ranges)
+}
+
+// RemoveDependency removes the statements of Routes that assign the
+// identifier dependency, e.g. authService, or that are the statement
+// dependency, compared the same way AddDependencies skips existing ones.
+// The imports nothing uses anymore are removed with them.
+func (r *astParser) RemoveDependency(dependency string) (bool, error) {
+	funcDecl := r.findFunc(DependencyTarget.Function)
+	if funcDecl == nil {
+		return false, r.CheckFunction(DependencyTarget.Function)
+	}
+
+	matches := func(stmt ast.Stmt) bool { return assigns(stmt, dependency) }
+	if !token.IsIdentifier(dependency) {
+		code, err := parseStmt(dependency)
+		if err != nil {
+			return false, err
+		}
+		matches = func(stmt ast.Stmt) bool { return isStmtExists(stmt, code) }
+	}
+
+	ranges := [][2]int{}
+	for _, stmt := range funcDecl.Body.List {
+		if matches(stmt) {
+			ranges = append(ranges, r.stmtRange(stmt))
+		}
+	}
+	return r.removeAndPrune(ranges)
+}
+
+// RemoveRoute removes the routes of Routes registered for method and
+// path, e.g. GET and /login, directly on the router, on a chained group or
+// on a group variable. The imports nothing uses anymore are removed with
+// them.
+func (r *astParser) RemoveRoute(method string, routePath string) (bool, error) {
+	funcDecl := r.findFunc(RouteTarget.Function)
+	if funcDecl == nil {
+		return false, r.CheckFunction(RouteTarget.Function)
+	}
+
+	// the prefixes of the group variables, e.g. adminGroup: /admin
+	groups := map[string]string{}
+	for _, stmt := range funcDecl.Body.List {
+		if assign, ok := stmt.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 && len(assign.Rhs) == 1 {
+			ident, ok := assign.Lhs[0].(*ast.Ident)
+			if prefix, isGroup := r.groupPrefix(assign.Rhs[0], groups); ok && isGroup {
+				groups[ident.Name] = prefix
+			}
+		}
+	}
+
+	ranges := [][2]int{}
+	for _, stmt := range funcDecl.Body.List {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		selExpr, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || selExpr.Sel.Name != method {
+			continue
+		}
+		relative, ok := stringLit(call.Args[0])
+		if !ok {
+			continue
+		}
+		prefix, ok := r.groupPrefix(selExpr.X, groups)
+		if ok && joinPaths(prefix, relative) == routePath {
+			ranges = append(ranges, r.stmtRange(stmt))
+		}
+	}
+	return r.removeAndPrune(ranges)
+}
+
+// groupPrefix returns the path prefix of the routes registered on expr:
+// "" for the router, the prefix of a group variable, or the joined
+// prefixes of chained router.Group calls.
+func (r *astParser) groupPrefix(expr ast.Expr, groups map[string]string) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if x.Name == RouteTarget.Receiver {
+			return "", true
+		}
+		prefix, ok := groups[x.Name]
+		return prefix, ok
+	case *ast.CallExpr:
+		selExpr, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok || selExpr.Sel.Name != "Group" || len(x.Args) == 0 {
+			return "", false
+		}
+		relative, ok := stringLit(x.Args[0])
+		if !ok {
+			return "", false
+		}
+		prefix, ok := r.groupPrefix(selExpr.X, groups)
+		return joinPaths(prefix, relative), ok
+	}
+	return "", false
+}
+
+// joinPaths joins a group prefix and a route path the way gin does.
+func joinPaths(prefix string, relative string) string {
+	if relative == "" {
+		return prefix
+	}
+	joined := path.Join(prefix, relative)
+	if strings.HasSuffix(relative, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+// assigns reports whether stmt declares or assigns the identifier name.
+func assigns(stmt ast.Stmt, name string) bool {
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range stmt.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+				return true
+			}
+		}
+	case *ast.DeclStmt:
+		genDecl, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok {
+			return false
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for _, ident := range valueSpec.Names {
+				if ident.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// versionSuffix matches the major version element of an import path, as
+// in github.com/golang-jwt/jwt/v5.
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName returns the name an import is referred to by: its alias, or
+// the package name told from its path. It reports false when neither is
+// known.
+func importName(importSpec *ast.ImportSpec) (string, bool) {
+	if importSpec.Name != nil {
+		return importSpec.Name.Name, true
+	}
+	importPath, err := strconv.Unquote(importSpec.Path.Value)
+	if err != nil {
+		return "", false
+	}
+	return packageName(importPath)
+}
+
+// packageName returns the package name told from an import path, which is
+// its last element, before the major version if any. It reports false when
+// that is not an identifier, as in gopkg.in/yaml.v3.
+func packageName(importPath string) (string, bool) {
+	name := path.Base(importPath)
+	if versionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	return name, token.IsIdentifier(name)
+}
+
+// selectorBases returns the identifiers of node that selectors are made
+// on, e.g. jwt of jwt.New, which are the names of the imports it uses.
+func selectorBases(node ast.Node) map[string]bool {
+	bases := map[string]bool{}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if selExpr, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := selExpr.X.(*ast.Ident); ok {
+				bases[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return bases
+}
+
+// PruneImports removes the imports the file referred to by one of names
+// and no longer uses, and returns their paths. Blank and dot imports, and
+// imports none of whose possible names is in names, are kept, so an import
+// the file did not use before is left alone.
+func (r *astParser) PruneImports(names []string) ([]string, error) {
+	used := selectorBases(r.file)
+	pruned := []string{}
+	for _, importSpec := range r.file.Imports {
+		candidates := format.ImportNames(importSpec)
+		if slices.ContainsFunc(candidates, func(name string) bool {
+			return name == "_" || name == "." || used[name]
+		}) || !slices.ContainsFunc(candidates, func(name string) bool { return slices.Contains(names, name) }) {
+			continue
+		}
+		importPath, _ := strconv.Unquote(importSpec.Path.Value)
+		pruned = append(pruned, importPath)
+	}
+
+	for _, importPath := range pruned {
+		if _, err := r.RemoveImport(importPath); err != nil {
+			return nil, err
+		}
+	}
+	return pruned, nil
+}
+
+// removeAndPrune removes ranges, then the imports only the removed code
+// used.
+func (r *astParser) removeAndPrune(ranges [][2]int) (bool, error) {
+	if len(ranges) == 0 {
+		return false, nil
+	}
+	names := []string{}
+	ast.Inspect(r.file, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		for _, rng := range ranges {
+			if r.offset(n.Pos()) >= rng[0] && r.offset(n.End()) <= rng[1] {
+				for name := range selectorBases(n) {
+					names = append(names, name)
+				}
+				return false
+			}
+		}
+		return true
+	})
+	if err := r.remove(ranges); err != nil {
+		return false, err
+	}
+	_, err := r.PruneImports(names)
+	return true, err
+}
+
+// stmtRange returns the offsets of node with the comment at the end of its
+// line.
+func (r *astParser) stmtRange(node ast.Node) [2]int {
+	end := r.offset(node.End())
+	if comment := strings.TrimSpace(string(r.src[end:r.lineEnd(end)])); strings.HasPrefix(comment, "//") {
+		end = r.lineEnd(end)
+	}
+	return [2]int{r.offset(node.Pos()), end}
+}
@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HTTPMethods are the router methods that register a route for a path.
+var HTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "Any"}
+
+// RouteGroup is a group of routes created with
+// router.Group(prefix, middlewares...).
+type RouteGroup struct {
+	// Name is the variable the group is assigned to.
+	Name string
+	// Prefix is the path prefix of the group, e.g. /admin.
+	Prefix string
+	// Middlewares are the expressions of the middlewares of the group, e.g.
+	// middleware.AuthMiddleware(&authService).
+	Middlewares []string
+}
+
+// FindRouteGroup returns the group with prefix created from
+// target.Receiver in the target function, with the middlewares of its
+// Group call and of the Use calls on it, and reports whether there is one.
+func (r *astParser) FindRouteGroup(target Target, prefix string) (RouteGroup, bool) {
+	funcDecl := r.findFunc(target.Function)
+	if funcDecl == nil {
+		return RouteGroup{}, false
+	}
+	for _, stmt := range funcDecl.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		ident, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		selExpr, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || selExpr.Sel.Name != "Group" || callReceiver(call) != target.Receiver {
+			continue
+		}
+		if path, ok := stringLit(call.Args[0]); ok && path == prefix {
+			group := RouteGroup{Name: ident.Name, Prefix: prefix, Middlewares: r.exprs(call.Args[1:])}
+			group.Middlewares = append(group.Middlewares, r.usedMiddlewares(funcDecl, ident.Name)...)
+			return group, true
+		}
+	}
+	return RouteGroup{}, false
+}
+
+// usedMiddlewares returns the expressions of the middlewares the target
+// function adds to the group variable name with Use.
+func (r *astParser) usedMiddlewares(funcDecl *ast.FuncDecl, name string) []string {
+	middlewares := []string{}
+	for _, stmt := range funcDecl.Body.List {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		selExpr, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || selExpr.Sel.Name != "Use" {
+			continue
+		}
+		if ident, ok := selExpr.X.(*ast.Ident); ok && ident.Name == name {
+			middlewares = append(middlewares, r.exprs(call.Args)...)
+		}
+	}
+	return middlewares
+}
+
+// AddRouteGroup returns the variable of the group with group.Prefix in the
+// target function and whether it declared it. When there is none, it
+// declares group.Name as a new group of target.Receiver at the anchor of
+// target.
+func (r *astParser) AddRouteGroup(target Target, group RouteGroup) (string, bool, error) {
+	funcDecl := r.findFunc(target.Function)
+	if funcDecl == nil {
+		return "", false, r.CheckFunction(target.Function)
+	}
+	if existing, ok := r.FindRouteGroup(target, group.Prefix); ok {
+		return existing.Name, false, nil
+	}
+	if r.declares(funcDecl, group.Name) {
+		return "", false, fmt.Errorf("%s is already declared in %s", group.Name, target.Function)
+	}
+
+	args := append([]string{strconv.Quote(group.Prefix)}, group.Middlewares...)
+	code := fmt.Sprintf("%s := %s.Group(%s)", group.Name, target.Receiver, strings.Join(args, ", "))
+	if _, err := r.AddStatements(target, []string{code}); err != nil {
+		return "", false, err
+	}
+	return group.Name, true, nil
+}
+
+// MoveRoutes moves the routes of target.Receiver whose path is under prefix
+// into group, a group with that prefix, and returns the routes it moved as
+// they were. The routes keep their order and go after the last statement
+// of the group.
+func (r *astParser) MoveRoutes(target Target, prefix string, group string) ([]string, error) {
+	funcDecl := r.findFunc(target.Function)
+	if funcDecl == nil {
+		return nil, r.CheckFunction(target.Function)
+	}
+
+	moved := []string{}
+	codes := []string{}
+	ranges := [][2]int{}
+	for _, stmt := range funcDecl.Body.List {
+		call, path, ok := routeCall(stmt, target.Receiver)
+		if !ok {
+			continue
+		}
+		relative, ok := underPrefix(path, prefix)
+		if !ok {
+			continue
+		}
+
+		start, end := r.offset(stmt.Pos()), r.offset(stmt.End())
+		lit := call.Args[0]
+		code := group +
+			string(r.src[r.offset(call.Fun.(*ast.SelectorExpr).X.End()):r.offset(lit.Pos())]) +
+			strconv.Quote(relative) +
+			string(r.src[r.offset(lit.End()):end])
+		// keep the comment at the end of the line with the route
+		if comment := strings.TrimSpace(string(r.src[end:r.lineEnd(end)])); strings.HasPrefix(comment, "//") {
+			code += " " + comment
+			end = r.lineEnd(end)
+		}
+
+		moved = append(moved, string(r.src[start:r.offset(stmt.End())]))
+		codes = append(codes, code)
+		ranges = append(ranges, [2]int{start, end})
+	}
+	if len(moved) == 0 {
+		return moved, nil
+	}
+
+	if err := r.remove(ranges); err != nil {
+		return nil, err
+	}
+	groupTarget := Target{Function: target.Function, Receiver: group, Anchor: AnchorAfter}
+	if _, err := r.AddStatements(groupTarget, codes); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+// routeCall returns the call and the path of stmt when it registers a
+// route directly on receiver, such as router.GET("/", handler).
+func routeCall(stmt ast.Stmt, receiver string) (*ast.CallExpr, string, bool) {
+	exprStmt, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil, "", false
+	}
+	call, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil, "", false
+	}
+	selExpr, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !slices.Contains(HTTPMethods, selExpr.Sel.Name) {
+		return nil, "", false
+	}
+	ident, ok := selExpr.X.(*ast.Ident)
+	if !ok || ident.Name != receiver {
+		return nil, "", false
+	}
+	path, ok := stringLit(call.Args[0])
+	return call, path, ok
+}
+
+// underPrefix reports whether path is prefix or below it, and returns path
+// relative to prefix.
+func underPrefix(path string, prefix string) (string, bool) {
+	if prefix == "/" {
+		return path, true
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if path == prefix {
+		return "", true
+	}
+	if strings.HasPrefix(path, prefix+"/") {
+		return strings.TrimPrefix(path, prefix), true
+	}
+	return "", false
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// declares reports whether funcDecl declares name as a parameter or with
+// := in its body.
+func (r *astParser) declares(funcDecl *ast.FuncDecl, name string) bool {
+	for _, field := range funcDecl.Type.Params.List {
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return true
+			}
+		}
+	}
+	for _, stmt := range funcDecl.Body.List {
+		if _, ok := stmt.(*ast.AssignStmt); ok && r.matches(Target{Receiver: name}, stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// remove deletes the ranges of offsets from the source, with their whole
+// lines when nothing else is on them, and parses the result.
+func (r *astParser) remove(ranges [][2]int) error {
+	src := r.src
+	for i := len(ranges) - 1; i >= 0; i-- {
+		start, end := ranges[i][0], ranges[i][1]
+		lineStart := strings.LastIndexByte(string(src[:start]), '\n') + 1
+		lineEnd := len(src)
+		if j := strings.IndexByte(string(src[end:]), '\n'); j >= 0 {
+			lineEnd = end + j
+		}
+		if strings.TrimSpace(string(src[lineStart:start])) == "" && strings.TrimSpace(string(src[end:lineEnd])) == "" {
+			start = lineStart
+			end = min(lineEnd+1, len(src))
+		}
+		src = append(src[:start:start], src[end:]...)
+	}
+	return r.parse(src)
+}
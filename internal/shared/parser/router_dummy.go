@@ -4,12 +4,15 @@ import (
 	"fmt"
 )
 
+// Routes registers the routes of the application.
 func Routes() {
+	// the router every route is registered on
 	var router = NewRouter()
 	_ = "bar"
 
-	router.GET("/", func() { fmt.Println("Hello, World!") })
+	router.GET("/", func() { fmt.Println("Hello, World!") }) // home page
 
+	// keep this comment at the end
 }
 
 type Router interface {
@@ -0,0 +1,308 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"go.yaml.in/yaml/v3"
+)
+
+// FileName is the project descriptor at the root of every generated project.
+const FileName = "gen.yaml"
+
+// SchemaVersion is the version of the descriptor layout written by this
+// binary. Older descriptors are migrated when they are parsed.
+//
+// Version 2 records the schematic, template and checksums of every
+// generated file, where version 1 only listed their paths.
+const SchemaVersion = 2
+
+const header = "# Project descriptor maintained by gomakase. Commands read and update it,\n# edit it with care.\n"
+
+// Supported values for the project options.
+var (
+	Databases      = []string{"sqlite", "postgres"}
+	Frontends      = []string{"alpine"}
+	HTTPFrameworks = []string{"gin"}
+)
+
+type Project struct {
+	SchemaVersion    int               `yaml:"schemaVersion"`
+	Name             string            `yaml:"name"`
+	Module           string            `yaml:"module"`
+	GeneratorVersion string            `yaml:"generatorVersion"`
+	Options          Options           `yaml:"options"`
+	Plugins          []Plugin          `yaml:"plugins,omitempty"`
+	Contexts         []string          `yaml:"contexts,omitempty"`
+	Variables        map[string]string `yaml:"variables,omitempty"`
+	Files            []GeneratedFile   `yaml:"files,omitempty"`
+}
+
+// GeneratedFile records where a generated file came from, so it can be
+// rendered again and compared with the working tree. Files migrated from
+// schema version 1 only have a Path.
+type GeneratedFile struct {
+	Path string `yaml:"path"`
+	// Schematic is the directory of the schematic under schematics/, e.g.
+	// project, context or plugins/auth.
+	Schematic string `yaml:"schematic,omitempty"`
+	// Context is the name of the context a context file was rendered for.
+	Context  string `yaml:"context,omitempty"`
+	Template string `yaml:"template,omitempty"`
+	// TemplateChecksum is the checksum of the rendered template and Checksum
+	// the one of the file as gomakase last wrote it. They differ once
+	// plugins have edited the file.
+	TemplateChecksum string `yaml:"templateChecksum,omitempty"`
+	Checksum         string `yaml:"checksum,omitempty"`
+}
+
+// UnmarshalYAML also accepts the plain paths of schema version 1.
+func (f *GeneratedFile) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*f = GeneratedFile{Path: node.Value}
+		return nil
+	}
+	type plain GeneratedFile
+	return node.Decode((*plain)(f))
+}
+
+// Checksum returns the checksum recorded for generated content.
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+type Options struct {
+	Database      string `yaml:"database"`
+	Frontend      string `yaml:"frontend"`
+	HTTPFramework string `yaml:"httpFramework"`
+}
+
+type Plugin struct {
+	Name      string            `yaml:"name"`
+	Version   string            `yaml:"version"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+// DefaultOptions returns the options used when none are chosen.
+func DefaultOptions() Options {
+	return Options{
+		Database:      Databases[0],
+		Frontend:      Frontends[0],
+		HTTPFramework: HTTPFrameworks[0],
+	}
+}
+
+// Validate reports an error for options this binary cannot generate.
+func (o Options) Validate() error {
+	if !slices.Contains(Databases, o.Database) {
+		return fmt.Errorf("unsupported database %q, expected one of %v", o.Database, Databases)
+	}
+	if !slices.Contains(Frontends, o.Frontend) {
+		return fmt.Errorf("unsupported frontend %q, expected one of %v", o.Frontend, Frontends)
+	}
+	if !slices.Contains(HTTPFrameworks, o.HTTPFramework) {
+		return fmt.Errorf("unsupported HTTP framework %q, expected one of %v", o.HTTPFramework, HTTPFrameworks)
+	}
+	return nil
+}
+
+// Parse decodes a project descriptor, migrating older schema versions to
+// SchemaVersion.
+func Parse(content []byte) (*Project, error) {
+	var p Project
+	err := yaml.Unmarshal(content, &p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", FileName, err)
+	}
+	if p.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%s has schema version %d, this gomakase supports up to %d", FileName, p.SchemaVersion, SchemaVersion)
+	}
+	if p.SchemaVersion < 1 {
+		migrateV0(&p)
+	}
+	if p.SchemaVersion < 2 {
+		// the plain paths of version 1 are decoded by GeneratedFile
+		p.SchemaVersion = 2
+	}
+	if p.Module == "" {
+		return nil, fmt.Errorf("%s has no module", FileName)
+	}
+	return &p, nil
+}
+
+// migrateV0 upgrades a descriptor written before schema versioning, which
+// only had module, generatorVersion and files.
+func migrateV0(p *Project) {
+	p.SchemaVersion = 1
+	if p.Name == "" {
+		p.Name = path.Base(p.Module)
+	}
+	defaults := DefaultOptions()
+	if p.Options.Database == "" {
+		p.Options.Database = defaults.Database
+	}
+	if p.Options.Frontend == "" {
+		p.Options.Frontend = defaults.Frontend
+	}
+	if p.Options.HTTPFramework == "" {
+		p.Options.HTTPFramework = defaults.HTTPFramework
+	}
+}
+
+// Marshal encodes the descriptor at the current schema version.
+func (p *Project) Marshal() ([]byte, error) {
+	p.SchemaVersion = SchemaVersion
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err := encoder.Encode(p)
+	if err != nil {
+		return nil, fmt.Errorf("error writing %s: %w", FileName, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ErrNotFound is returned by FindRoot when no directory contains FileName.
+var ErrNotFound = errors.New(FileName + " not found")
+
+// FindRoot returns the project root for dir: the closest directory, starting
+// at dir and walking up its parents, that contains FileName.
+func FindRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	start := dir
+	for {
+		info, err := os.Stat(filepath.Join(dir, FileName))
+		if err == nil && !info.IsDir() {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w in %s or any parent directory", ErrNotFound, start)
+		}
+		dir = parent
+	}
+}
+
+// Load reads the descriptor from the project root.
+func Load(file file.File) (*Project, error) {
+	content, err := file.ReadFile(FileName)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Save writes the descriptor to the project root.
+func Save(file file.File, p *Project) error {
+	content, err := p.Marshal()
+	if err != nil {
+		return err
+	}
+	return file.CreateFile(FileName, content)
+}
+
+// TemplateData returns the values the project provides to templates: the
+// custom variables, Module, ProjectName, ProjectSlug and Database.
+func (p *Project) TemplateData() map[string]string {
+	data := make(map[string]string)
+	for name, value := range p.Variables {
+		data[name] = value
+	}
+	data["Module"] = p.Module
+	data["ProjectName"] = p.Name
+	data["ProjectSlug"] = Slug(p.Name)
+	data["Database"] = p.Options.Database
+	return data
+}
+
+// Slug returns name in lowercase letters, digits and dashes, which is safe
+// for docker-compose services, container names and npm packages.
+func Slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return "app"
+	}
+	return b.String()
+}
+
+func (p *Project) HasPlugin(name string) bool {
+	return slices.ContainsFunc(p.Plugins, func(plugin Plugin) bool {
+		return plugin.Name == name
+	})
+}
+
+// AddPlugin records an installed plugin and the variables it was added
+// with, replacing them if it is already listed.
+func (p *Project) AddPlugin(name string, version string, variables map[string]string) {
+	for i, plugin := range p.Plugins {
+		if plugin.Name == name {
+			p.Plugins[i].Version = version
+			p.Plugins[i].Variables = variables
+			return
+		}
+	}
+	p.Plugins = append(p.Plugins, Plugin{Name: name, Version: version, Variables: variables})
+}
+
+func (p *Project) HasContext(name string) bool {
+	return slices.Contains(p.Contexts, name)
+}
+
+func (p *Project) AddContext(name string) {
+	if !p.HasContext(name) {
+		p.Contexts = append(p.Contexts, name)
+	}
+}
+
+// AddFiles records generated files, replacing the ones already listed
+// with the same path.
+func (p *Project) AddFiles(files ...GeneratedFile) {
+	for _, file := range files {
+		i := slices.IndexFunc(p.Files, func(f GeneratedFile) bool {
+			return f.Path == file.Path
+		})
+		if i >= 0 {
+			p.Files[i] = file
+			continue
+		}
+		p.Files = append(p.Files, file)
+	}
+}
+
+// RecordWrite updates the checksum of a generated file that gomakase
+// changed after generating it, e.g. with an AST edit. Files that are not
+// listed are ignored.
+func (p *Project) RecordWrite(path string, content []byte) {
+	for i, file := range p.Files {
+		if file.Path == path {
+			p.Files[i].Checksum = Checksum(content)
+		}
+	}
+}
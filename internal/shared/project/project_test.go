@@ -0,0 +1,188 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestProject_ParseLegacy(t *testing.T) {
+	input := `module: "github.com/acme/billing"
+
+# Version of the generator that created this project
+generatorVersion: "1.0.0"
+files:
+  - go.mod
+`
+
+	p, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Error parsing project: %v", err)
+	}
+	assert.Equal(t, p.SchemaVersion, SchemaVersion)
+	assert.Equal(t, p.Name, "billing")
+	assert.Equal(t, p.Module, "github.com/acme/billing")
+	assert.Equal(t, p.GeneratorVersion, "1.0.0")
+	assert.Equal(t, p.Options, DefaultOptions())
+	assert.Equal(t, p.Files, []GeneratedFile{{Path: "go.mod"}})
+}
+
+func TestProject_ParseV1(t *testing.T) {
+	input := `schemaVersion: 1
+name: billing
+module: github.com/acme/billing
+generatorVersion: 1.0.0
+options:
+  database: sqlite
+  frontend: alpine
+  httpFramework: gin
+files:
+  - go.mod
+  - cmd/server/main.go
+`
+
+	p, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Error parsing project: %v", err)
+	}
+	assert.Equal(t, p.Files, []GeneratedFile{{Path: "go.mod"}, {Path: "cmd/server/main.go"}})
+
+	content, err := p.Marshal()
+	if err != nil {
+		t.Fatalf("Error marshalling project: %v", err)
+	}
+	if !strings.Contains(string(content), "schemaVersion: 2\n") || !strings.Contains(string(content), "- path: go.mod\n") {
+		t.Fatalf("Expected a version 2 descriptor:\n%s", content)
+	}
+}
+
+func TestProject_MarshalRoundTrip(t *testing.T) {
+	p := &Project{
+		Name:             "billing",
+		Module:           "github.com/acme/billing",
+		GeneratorVersion: "1.0.0",
+		Options:          Options{Database: "postgres", Frontend: "alpine", HTTPFramework: "gin"},
+		Variables:        map[string]string{"Author": "Acme"},
+	}
+	p.AddPlugin("auth", "1.0.0", nil)
+	p.AddPlugin("auth", "1.1.0", map[string]string{"CookieName": "session"})
+	p.AddContext("order")
+	p.AddContext("order")
+	p.AddFiles(
+		GeneratedFile{Path: "go.mod", Schematic: "project", Template: "go.mod.tmpl"},
+		GeneratedFile{Path: "cmd/server/main.go", Schematic: "project", Template: "main.go.tmpl"},
+	)
+	p.AddFiles(GeneratedFile{
+		Path:             "go.mod",
+		Schematic:        "project",
+		Template:         "go.mod.tmpl",
+		TemplateChecksum: Checksum([]byte("module github.com/acme/billing\n")),
+		Checksum:         Checksum([]byte("module github.com/acme/billing\n")),
+	})
+	p.RecordWrite("cmd/server/main.go", []byte("package main\n"))
+
+	content, err := p.Marshal()
+	if err != nil {
+		t.Fatalf("Error marshalling project: %v", err)
+	}
+	if !strings.Contains(string(content), "schemaVersion: 2\n") {
+		t.Fatalf("Expected schemaVersion in:\n%s", content)
+	}
+
+	parsed, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Error parsing project: %v", err)
+	}
+	assert.Equal(t, parsed, p)
+	assert.Equal(t, parsed.Plugins, []Plugin{{Name: "auth", Version: "1.1.0", Variables: map[string]string{"CookieName": "session"}}})
+	assert.Equal(t, parsed.Contexts, []string{"order"})
+	assert.Equal(t, len(parsed.Files), 2)
+	assert.Equal(t, parsed.Files[0].Checksum, Checksum([]byte("module github.com/acme/billing\n")))
+	assert.Equal(t, parsed.Files[1].TemplateChecksum, "")
+	assert.Equal(t, parsed.Files[1].Checksum, Checksum([]byte("package main\n")))
+	assert.Equal(t, parsed.HasPlugin("auth"), true)
+	assert.Equal(t, parsed.HasContext("product"), false)
+}
+
+func TestProject_ParseNewerSchema(t *testing.T) {
+	_, err := Parse([]byte("schemaVersion: 99\nmodule: demo\n"))
+	if err == nil {
+		t.Fatalf("Expected an error for a newer schema version")
+	}
+}
+
+func TestProject_ParseWithoutModule(t *testing.T) {
+	_, err := Parse([]byte("generatorVersion: 1.0.0\n"))
+	if err == nil {
+		t.Fatalf("Expected an error for a missing module")
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.Equal(t, DefaultOptions().Validate(), nil)
+
+	options := DefaultOptions()
+	options.Database = "mysql"
+	if options.Validate() == nil {
+		t.Fatalf("Expected an error for an unsupported database")
+	}
+}
+
+func TestProject_TemplateData(t *testing.T) {
+	p := &Project{
+		Name:      "billing",
+		Module:    "github.com/acme/billing",
+		Options:   DefaultOptions(),
+		Variables: map[string]string{"Author": "Acme", "Module": "ignored"},
+	}
+
+	data := p.TemplateData()
+	assert.Equal(t, data["Module"], "github.com/acme/billing")
+	assert.Equal(t, data["ProjectName"], "billing")
+	assert.Equal(t, data["Database"], "sqlite")
+	assert.Equal(t, data["Author"], "Acme")
+}
+
+func TestFindRoot(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "internal", "order", "domain")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Error creating directories: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte("module: demo\n"), 0644); err != nil {
+		t.Fatalf("Error writing %s: %v", FileName, err)
+	}
+
+	for _, dir := range []string{root, nested} {
+		found, err := FindRoot(dir)
+		if err != nil {
+			t.Fatalf("Error finding root from %s: %v", dir, err)
+		}
+		assert.Equal(t, found, root)
+	}
+}
+
+func TestFindRoot_NotFound(t *testing.T) {
+	_, err := FindRoot(t.TempDir())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"myapp":        "myapp",
+		"My_App":       "my-app",
+		"my.app v2":    "my-app-v2",
+		"--billing--":  "billing",
+		"Shop__API.v1": "shop-api-v1",
+		"!!!":          "app",
+	}
+	for name, want := range tests {
+		assert.Equal(t, Slug(name), want)
+	}
+}
@@ -0,0 +1,124 @@
+package schematic
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+)
+
+// Root is the directory every schematic lives under, e.g.
+// schematics/plugins/auth/schematic.yaml.
+const Root = "schematics"
+
+// NewFS returns the schematics in base overlaid with the schematics in dirs.
+// Each dir has the same layout as the Root directory of base (project,
+// context, plugins/<name>), and a file in an earlier dir takes precedence
+// over the same file in later dirs and in base. Directory listings are
+// merged, so a dir can add new plugins next to the built-in ones.
+func NewFS(base fs.FS, dirs ...string) fs.FS {
+	layers := []fs.FS{}
+	for _, dir := range dirs {
+		layers = append(layers, &prefixFS{prefix: Root, fsys: os.DirFS(dir)})
+	}
+	layers = append(layers, base)
+	return &overlayFS{layers: layers}
+}
+
+type overlayFS struct {
+	layers []fs.FS
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	for _, layer := range o.layers {
+		f, err := layer.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (o *overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := []fs.DirEntry{}
+	found := false
+	for _, layer := range o.layers {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			exists := slices.ContainsFunc(entries, func(e fs.DirEntry) bool {
+				return e.Name() == entry.Name()
+			})
+			if !exists {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// prefixFS serves fsys as if it were mounted at prefix.
+type prefixFS struct {
+	prefix string
+	fsys   fs.FS
+}
+
+func (p *prefixFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == p.prefix {
+		return p.fsys.Open(".")
+	}
+	rel, ok := strings.CutPrefix(name, p.prefix+"/")
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return p.fsys.Open(path.Clean(rel))
+}
+
+// OverrideDir is the directory of a project with its own versions of
+// templates, laid out as <schematic>/<template>, e.g.
+// .gomakase/templates/context/handler.go.tmpl.
+const OverrideDir = ".gomakase/templates"
+
+// TemplatePath returns the path of a template of the schematic name, e.g.
+// context or plugins/auth, in a schematics filesystem.
+func TemplatePath(name, template string) string {
+	return path.Join(Root, name, "templates", template)
+}
+
+// OverridePath returns the path of the project's override of a template of
+// the schematic name.
+func OverridePath(name, template string) string {
+	return path.Join(OverrideDir, name, template)
+}
+
+// ReadTemplate reads a template of the schematic name. The project's
+// override in files is preferred over the template in schematicsFS.
+func ReadTemplate(files file.File, schematicsFS fs.FS, name, template string) ([]byte, error) {
+	content, err := files.ReadFile(OverridePath(name, template))
+	if !errors.Is(err, fs.ErrNotExist) {
+		return content, err
+	}
+	return fs.ReadFile(schematicsFS, TemplatePath(name, template))
+}
@@ -0,0 +1,101 @@
+package schematic
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Error creating directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+
+func TestNewFS(t *testing.T) {
+	base := fstest.MapFS{
+		"schematics/plugins/auth/schematic.yaml":      {Data: []byte("builtin auth")},
+		"schematics/plugins/auth/templates/a.go.tmpl": {Data: []byte("builtin template")},
+		"schematics/context/schematic.yaml":           {Data: []byte("builtin context")},
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plugins", "auth", "schematic.yaml"), "custom auth")
+	writeFile(t, filepath.Join(dir, "plugins", "payments", "schematic.yaml"), "custom payments")
+
+	fsys := NewFS(base, dir)
+
+	content, err := fs.ReadFile(fsys, "schematics/plugins/auth/schematic.yaml")
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	assert.Equal(t, string(content), "custom auth")
+
+	content, err = fs.ReadFile(fsys, "schematics/plugins/auth/templates/a.go.tmpl")
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	assert.Equal(t, string(content), "builtin template")
+
+	entries, err := fs.ReadDir(fsys, "schematics/plugins")
+	if err != nil {
+		t.Fatalf("Error reading directory: %v", err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, names, []string{"auth", "payments"})
+
+	_, err = fs.ReadFile(fsys, "schematics/plugins/billing/schematic.yaml")
+	if !os.IsNotExist(err) {
+		t.Fatalf("Expected a not exist error, got: %v", err)
+	}
+}
+
+func TestNewFS_MissingDir(t *testing.T) {
+	base := fstest.MapFS{
+		"schematics/plugins/auth/schematic.yaml": {Data: []byte("builtin auth")},
+	}
+
+	fsys := NewFS(base, filepath.Join(t.TempDir(), "missing"))
+
+	entries, err := fs.ReadDir(fsys, "schematics/plugins")
+	if err != nil {
+		t.Fatalf("Error reading directory: %v", err)
+	}
+	assert.Equal(t, len(entries), 1)
+}
+
+func TestReadTemplate(t *testing.T) {
+	base := fstest.MapFS{
+		"schematics/context/templates/handler.go.tmpl": {Data: []byte("builtin handler")},
+		"schematics/context/templates/entity.go.tmpl":  {Data: []byte("builtin entity")},
+	}
+	files := file.New(file.NewMemFS())
+	files.CreateFile(".gomakase/templates/context/handler.go.tmpl", []byte("project handler"))
+
+	content, err := ReadTemplate(files, base, "context", "handler.go.tmpl")
+	if err != nil {
+		t.Fatalf("Error reading template: %v", err)
+	}
+	assert.Equal(t, string(content), "project handler")
+
+	content, err = ReadTemplate(files, base, "context", "entity.go.tmpl")
+	if err != nil {
+		t.Fatalf("Error reading template: %v", err)
+	}
+	assert.Equal(t, string(content), "builtin entity")
+
+	_, err = ReadTemplate(files, base, "context", "missing.go.tmpl")
+	assert.Equal(t, errors.Is(err, fs.ErrNotExist), true)
+}
@@ -0,0 +1,94 @@
+package spec
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"go.yaml.in/yaml/v3"
+)
+
+// FileName is the spec gomakase apply reads by default.
+const FileName = "gomakase.spec.yaml"
+
+// Spec declares the state of a project: gomakase apply generates the
+// project and adds the contexts and plugins that are missing.
+type Spec struct {
+	Module    string            `yaml:"module"`
+	Name      string            `yaml:"name,omitempty"`
+	Options   project.Options   `yaml:"options"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Contexts  []string          `yaml:"contexts,omitempty"`
+	Plugins   []Plugin          `yaml:"plugins,omitempty"`
+}
+
+type Plugin struct {
+	Name      string            `yaml:"name"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+// Parse decodes and validates a spec. Missing options are set to their
+// defaults and a missing name to the last element of the module.
+func Parse(content []byte) (*Spec, error) {
+	var s Spec
+	err := yaml.Unmarshal(content, &s)
+	if err != nil {
+		return nil, fmt.Errorf("error reading spec: %w", err)
+	}
+	if s.Module == "" {
+		return nil, fmt.Errorf("spec has no module")
+	}
+	if s.Name == "" {
+		s.Name = path.Base(s.Module)
+	}
+
+	defaults := project.DefaultOptions()
+	if s.Options.Database == "" {
+		s.Options.Database = defaults.Database
+	}
+	if s.Options.Frontend == "" {
+		s.Options.Frontend = defaults.Frontend
+	}
+	if s.Options.HTTPFramework == "" {
+		s.Options.HTTPFramework = defaults.HTTPFramework
+	}
+	err = s.Options.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("spec has invalid options: %w", err)
+	}
+
+	for i, context := range s.Contexts {
+		if context == "" {
+			return nil, fmt.Errorf("spec context %d has no name", i+1)
+		}
+	}
+	for i, plugin := range s.Plugins {
+		if plugin.Name == "" {
+			return nil, fmt.Errorf("spec plugin %d has no name", i+1)
+		}
+	}
+	return &s, nil
+}
+
+// Load reads the spec name from file.
+func Load(file file.File, name string) (*Spec, error) {
+	content, err := file.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Conflicts returns why p cannot be brought to the spec: apply never
+// changes the module or the options of an existing project.
+func (s *Spec) Conflicts(p *project.Project) []string {
+	conflicts := []string{}
+	if s.Module != p.Module {
+		conflicts = append(conflicts, fmt.Sprintf("module is %s in the spec but %s in %s", s.Module, p.Module, project.FileName))
+	}
+	if s.Options != p.Options {
+		conflicts = append(conflicts, fmt.Sprintf("options are %+v in the spec but %+v in %s", s.Options, p.Options, project.FileName))
+	}
+	return conflicts
+}
@@ -0,0 +1,58 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestParse(t *testing.T) {
+	content := []byte(`module: github.com/acme/shop
+options:
+  database: postgres
+contexts:
+  - order
+  - product
+plugins:
+  - name: auth
+    variables:
+      Module: github.com/acme/shop
+`)
+
+	s, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Error parsing spec: %v", err)
+	}
+	assert.Equal(t, s.Name, "shop")
+	assert.Equal(t, s.Options, project.Options{Database: "postgres", Frontend: "alpine", HTTPFramework: "gin"})
+	assert.Equal(t, s.Contexts, []string{"order", "product"})
+	assert.Equal(t, s.Plugins, []Plugin{{Name: "auth", Variables: map[string]string{"Module": "github.com/acme/shop"}}})
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"options:\n  database: sqlite\n",
+		"module: shop\noptions:\n  database: mysql\n",
+		"module: shop\nplugins:\n  - variables:\n      Foo: bar\n",
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt)); err == nil {
+			t.Fatalf("Expected an error for spec:\n%s", tt)
+		}
+	}
+}
+
+func TestSpec_Conflicts(t *testing.T) {
+	s, err := Parse([]byte("module: github.com/acme/shop\n"))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %v", err)
+	}
+
+	p := &project.Project{Module: "github.com/acme/shop", Options: project.DefaultOptions()}
+	assert.Equal(t, len(s.Conflicts(p)), 0)
+
+	p.Module = "github.com/acme/store"
+	p.Options.Database = "postgres"
+	assert.Equal(t, len(s.Conflicts(p)), 2)
+}
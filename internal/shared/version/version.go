@@ -0,0 +1,54 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the gomakase binary. It is written to gen.yaml
+// as generatorVersion when a project is created.
+const Version = "1.0.0"
+
+// Compatible reports whether a project generated by generatorVersion can be
+// managed by this binary. Projects must share the major version and must not
+// be newer than the binary.
+func Compatible(generatorVersion string) error {
+	project, err := parse(generatorVersion)
+	if err != nil {
+		return err
+	}
+	binary, err := parse(Version)
+	if err != nil {
+		return err
+	}
+
+	if project[0] != binary[0] {
+		return fmt.Errorf("project was generated by %s, which is not compatible with %s", generatorVersion, Version)
+	}
+	for i := range project {
+		if project[i] > binary[i] {
+			return fmt.Errorf("project was generated by %s, which is newer than %s", generatorVersion, Version)
+		}
+		if project[i] < binary[i] {
+			break
+		}
+	}
+	return nil
+}
+
+func parse(v string) ([3]int, error) {
+	var parts [3]int
+	fields := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(fields) != 3 {
+		return parts, fmt.Errorf("invalid version %q", v)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, fmt.Errorf("invalid version %q", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
@@ -0,0 +1,28 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestCompatible(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: Version, expected: true},
+		{input: "v" + Version, expected: true},
+		{input: "0.9.0", expected: false},
+		{input: "1.0.1", expected: false},
+		{input: "1.1.0", expected: false},
+		{input: "2.0.0", expected: false},
+		{input: "", expected: false},
+		{input: "1.x.0", expected: false},
+	}
+
+	for _, tt := range tests {
+		err := Compatible(tt.input)
+		if (err == nil) != tt.expected {
+			t.Fatalf("Compatible(%q) = %v, expected compatible: %v", tt.input, err, tt.expected)
+		}
+	}
+}
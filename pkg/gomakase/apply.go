@@ -0,0 +1,141 @@
+package gomakase
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/command"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/naming"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/spec"
+)
+
+type (
+	// Spec declares the module, options, contexts and plugins of a project.
+	Spec       = spec.Spec
+	SpecPlugin = spec.Plugin
+)
+
+// SpecFileName is the spec gomakase apply reads by default.
+const SpecFileName = spec.FileName
+
+// ParseSpec decodes and validates a spec.
+func ParseSpec(content []byte) (*Spec, error) {
+	return spec.Parse(content)
+}
+
+type ApplyOptions struct {
+	// Spec is the state the project is brought to.
+	Spec *Spec
+
+	// Schematics defaults to the built-in schematics.
+	Schematics fs.FS
+	// Output is rooted at the project directory.
+	Output Filesystem
+	// Hooks runs go mod tidy and npm install once, when anything was
+	// generated. A nil Hooks runs nothing.
+	Hooks HookRunner
+	// Events receives every event as it happens.
+	Events Emitter
+}
+
+// Apply brings the project in opts.Output to opts.Spec. It generates the
+// project when there is no gen.yaml yet, then adds the contexts and plugins
+// that are missing and reports the ones already there as satisfied, so
+// applying the same spec twice changes nothing. Apply never changes the
+// module or the options of an existing project.
+func Apply(opts ApplyOptions) (Result, error) {
+	r := newRun("apply", opts.Events)
+	changed, err := apply(r, opts)
+	if err != nil {
+		return r.fail(err)
+	}
+	if changed {
+		err = runHooks(r, opts.Output, opts.Hooks, command.GoModTidy, command.NPMInstall)
+	}
+	return r.finish(err)
+}
+
+func apply(r *run, opts ApplyOptions) (bool, error) {
+	if opts.Spec == nil {
+		return false, errors.New("spec is required")
+	}
+	if opts.Output == nil {
+		return false, errors.New("output filesystem is required")
+	}
+	s := opts.Spec
+	file := file.New(opts.Output)
+
+	changed := false
+	if file.IsPathExists(project.FileName) {
+		p, err := project.Load(file)
+		if err != nil {
+			return false, fmt.Errorf("loading project descriptor: %w", err)
+		}
+		conflicts := s.Conflicts(p)
+		if len(conflicts) > 0 {
+			return false, fmt.Errorf("project does not match the spec: %s", strings.Join(conflicts, "; "))
+		}
+		r.recorder.Emit(Event{Type: Satisfied, Action: "project", Detail: s.Module})
+	} else {
+		err := newProject(r, ProjectOptions{
+			Module:        s.Module,
+			Name:          s.Name,
+			Database:      s.Options.Database,
+			Frontend:      s.Options.Frontend,
+			HTTPFramework: s.Options.HTTPFramework,
+			Variables:     s.Variables,
+			Schematics:    opts.Schematics,
+			Output:        opts.Output,
+		})
+		if err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	p, err := project.Load(file)
+	if err != nil {
+		return changed, fmt.Errorf("loading project descriptor: %w", err)
+	}
+
+	for _, name := range s.Contexts {
+		// contexts are recorded under their canonical name, e.g. order_item
+		// for OrderItem
+		if context, err := naming.ParseContext(name); err == nil && p.HasContext(context.Name) {
+			r.recorder.Emit(Event{Type: Satisfied, Action: "context", Detail: name})
+			continue
+		}
+		err := addContext(r, ContextOptions{
+			Name:       name,
+			Schematics: opts.Schematics,
+			Output:     opts.Output,
+		})
+		if err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	for _, plugin := range s.Plugins {
+		if p.HasPlugin(plugin.Name) {
+			r.recorder.Emit(Event{Type: Satisfied, Action: "plugin", Detail: plugin.Name})
+			continue
+		}
+		err := addPlugin(r, PluginOptions{
+			Name:       plugin.Name,
+			Variables:  plugin.Variables,
+			Schematics: opts.Schematics,
+			Output:     opts.Output,
+		})
+		if err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
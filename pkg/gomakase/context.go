@@ -0,0 +1,72 @@
+package gomakase
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/IrwantoCia/gomakase/internal/ctx_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/command"
+	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
+)
+
+type ContextOptions struct {
+	// Name is the name of the bounded context, e.g. order.
+	Name string
+
+	// Schematics defaults to the built-in schematics.
+	Schematics fs.FS
+	// Output is rooted at the project directory, next to gen.yaml.
+	Output Filesystem
+	// Hooks runs go mod tidy. A nil Hooks runs nothing.
+	Hooks HookRunner
+	// Events receives every event as it happens.
+	Events Emitter
+}
+
+// AddContext generates a bounded context into the project in opts.Output.
+func AddContext(opts ContextOptions) (Result, error) {
+	r := newRun("context", opts.Events)
+	err := addContext(r, opts)
+	if err != nil {
+		return r.fail(err)
+	}
+	err = runHooks(r, opts.Output, opts.Hooks, command.GoModTidy)
+	return r.finish(err)
+}
+
+func addContext(r *run, opts ContextOptions) error {
+	if opts.Name == "" {
+		return errors.New("context name is required")
+	}
+	if opts.Output == nil {
+		return errors.New("output filesystem is required")
+	}
+	schematics := schematicsOrDefault(opts.Schematics)
+	file := file.New(opts.Output)
+
+	p, err := project.Load(file)
+	if err != nil {
+		return fmt.Errorf("loading project descriptor: %w", err)
+	}
+
+	content, err := fs.ReadFile(schematics, path.Join(schematic.Root, "context", "schematic.yaml"))
+	if err != nil {
+		return fmt.Errorf("reading context schematic: %w", err)
+	}
+	contextSchematic, err := config.LoadSchematic[config.ContextSchematic](content)
+	if err != nil {
+		return fmt.Errorf("loading context schematic: %w", err)
+	}
+
+	ctxService := application.NewCtxService(file, p, contextSchematic, schematics, r.recorder)
+	err = ctxService.Generate(opts.Name)
+	if err != nil {
+		return fmt.Errorf("generating context: %w", err)
+	}
+	return nil
+}
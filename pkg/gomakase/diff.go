@@ -0,0 +1,51 @@
+package gomakase
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/IrwantoCia/gomakase/internal/diff_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+)
+
+type (
+	// FileDiff compares a generated file with its template rendered again.
+	FileDiff   = application.FileDiff
+	DiffStatus = application.Status
+)
+
+const (
+	DiffUnchanged       = application.Unchanged
+	DiffUserModified    = application.UserModified
+	DiffTemplateChanged = application.TemplateChanged
+	DiffBoth            = application.Both
+	DiffMissing         = application.Missing
+	DiffUnknown         = application.Unknown
+)
+
+type DiffOptions struct {
+	// Schematics defaults to the built-in schematics.
+	Schematics fs.FS
+	// Output is rooted at the project directory, next to gen.yaml. Diff
+	// only reads from it.
+	Output Filesystem
+}
+
+// Diff renders every template that produced a file of the project again,
+// in memory, and compares the result with the file in opts.Output. It tells
+// apart files the user modified, files whose template changed upstream and
+// files with both.
+func Diff(opts DiffOptions) ([]FileDiff, error) {
+	if opts.Output == nil {
+		return nil, errors.New("output filesystem is required")
+	}
+	file := file.New(opts.Output)
+	p, err := project.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("loading project descriptor: %w", err)
+	}
+	diffService := application.NewDiffService(file, p, schematicsOrDefault(opts.Schematics))
+	return diffService.Diff()
+}
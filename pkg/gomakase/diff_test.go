@@ -0,0 +1,101 @@
+package gomakase
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// overrideFS serves files over the ones of base.
+type overrideFS struct {
+	base  fs.FS
+	files fstest.MapFS
+}
+
+func (o overrideFS) Open(name string) (fs.File, error) {
+	if _, ok := o.files[name]; ok {
+		return o.files.Open(name)
+	}
+	return o.base.Open(name)
+}
+
+func statuses(t *testing.T, opts DiffOptions) map[string]DiffStatus {
+	t.Helper()
+	diffs, err := Diff(opts)
+	if err != nil {
+		t.Fatalf("Error diffing project: %v", err)
+	}
+	statuses := map[string]DiffStatus{}
+	for _, fileDiff := range diffs {
+		if fileDiff.Status != DiffUnchanged {
+			statuses[fileDiff.Path] = fileDiff.Status
+		}
+	}
+	return statuses
+}
+
+// tidyRunner stands in for go mod tidy, which adds the requirements of the
+// generated code to go.mod.
+type tidyRunner struct {
+	output *MemFS
+}
+
+func (r tidyRunner) Run(name string, args ...string) error {
+	if name != "go" {
+		return nil
+	}
+	content, err := r.output.ReadFile("go.mod")
+	if err != nil {
+		return err
+	}
+	return r.output.WriteFile("go.mod", append(content, "\nrequire github.com/gin-gonic/gin v1.10.0\n"...))
+}
+
+func TestDiff(t *testing.T) {
+	output := NewMemFS()
+	if _, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output, Hooks: tidyRunner{output}}); err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	if _, err := AddContext(ContextOptions{Name: "order", Output: output}); err != nil {
+		t.Fatalf("Error generating context: %v", err)
+	}
+	// plugins edit router.go after it was generated
+	if _, err := AddPlugin(PluginOptions{Name: "auth", Output: output}); err != nil {
+		t.Fatalf("Error adding plugin: %v", err)
+	}
+	assert.Equal(t, statuses(t, DiffOptions{Output: output}), map[string]DiffStatus{})
+
+	output.WriteFile("go.mod", []byte("module github.com/acme/demo\n"))
+	output.WriteFile("Makefile", []byte("run:\n\tgo run ./cmd/server\n"))
+	schematics := overrideFS{
+		base: Schematics(),
+		files: fstest.MapFS{
+			"schematics/project/templates/Makefile.tmpl":        {Data: []byte("build:\n\tgo build ./...\n")},
+			"schematics/project/templates/Dockerfile.tmpl":      {Data: []byte("FROM golang\n")},
+			"schematics/context/templates/entity.go.tmpl":       {Data: []byte("package domain\n\ntype {{ .ContextName | title }} struct{}\n")},
+			"schematics/plugins/auth/templates/login.html.tmpl": {Data: []byte("<form></form>\n")},
+		},
+	}
+	assert.Equal(t, statuses(t, DiffOptions{Schematics: schematics, Output: output}), map[string]DiffStatus{
+		"go.mod":                                DiffUserModified,
+		"Makefile":                              DiffBoth,
+		"Dockerfile":                            DiffTemplateChanged,
+		"internal/order/domain/order.entity.go": DiffTemplateChanged,
+		"web/views/login.html":                  DiffTemplateChanged,
+	})
+
+	// the diff goes from the template to the working tree
+	diffs, err := Diff(DiffOptions{Schematics: schematics, Output: output})
+	if err != nil {
+		t.Fatalf("Error diffing project: %v", err)
+	}
+	for _, fileDiff := range diffs {
+		if fileDiff.Path == "Makefile" &&
+			(!strings.Contains(fileDiff.Diff, "-build:") || !strings.Contains(fileDiff.Diff, "+run:")) {
+			t.Fatalf("Expected the diff from the template to the Makefile:\n%s", fileDiff.Diff)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package gomakase
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/IrwantoCia/gomakase/internal/eject_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
+)
+
+// TemplateOverrideDir is the directory of a project with its own versions
+// of templates, laid out as <schematic>/<template>. The context and plugin
+// generators use them instead of the templates in the schematics.
+const TemplateOverrideDir = schematic.OverrideDir
+
+type EjectOptions struct {
+	// Schematic is the schematic of the template, e.g. context or
+	// plugins/auth.
+	Schematic string
+	// Template is the name of the template, e.g. handler.go.tmpl.
+	Template string
+	// Force overwrites an existing override.
+	Force bool
+
+	// Schematics defaults to the built-in schematics.
+	Schematics fs.FS
+	// Output is rooted at the project directory.
+	Output Filesystem
+}
+
+// Eject copies a template from the schematics to the project's
+// TemplateOverrideDir for editing, and returns the path of the copy.
+func Eject(opts EjectOptions) (string, error) {
+	if opts.Output == nil {
+		return "", errors.New("output filesystem is required")
+	}
+	ejectService := application.NewEjectService(file.New(opts.Output), schematicsOrDefault(opts.Schematics))
+	return ejectService.Eject(opts.Schematic, opts.Template, opts.Force)
+}
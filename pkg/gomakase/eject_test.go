@@ -0,0 +1,41 @@
+package gomakase
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestEject(t *testing.T) {
+	output := NewMemFS()
+	if _, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output}); err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+
+	path, err := Eject(EjectOptions{Schematic: "context", Template: "handler.go.tmpl", Output: output})
+	if err != nil {
+		t.Fatalf("Error ejecting template: %v", err)
+	}
+	assert.Equal(t, path, ".gomakase/templates/context/handler.go.tmpl")
+	if _, err := Eject(EjectOptions{Schematic: "context", Template: "handler.go.tmpl", Output: output}); err == nil {
+		t.Fatalf("Expected an error when the override exists")
+	}
+	if _, err := Eject(EjectOptions{Schematic: "context", Template: "../../go.mod", Output: output}); err == nil {
+		t.Fatalf("Expected an error for an invalid template")
+	}
+
+	// the context generator prefers the project's copy
+	content, _ := output.ReadFile(path)
+	output.WriteFile(path, append([]byte("// Swagger annotations\n"), content...))
+	if _, err := AddContext(ContextOptions{Name: "order", Output: output}); err != nil {
+		t.Fatalf("Error generating context: %v", err)
+	}
+	handler, err := output.ReadFile("internal/order/delivery/order.handler.go")
+	if err != nil {
+		t.Fatalf("Error reading handler: %v", err)
+	}
+	if !strings.HasPrefix(string(handler), "// Swagger annotations\n") {
+		t.Fatalf("Expected the handler to use the override:\n%s", handler)
+	}
+}
@@ -0,0 +1,170 @@
+// Package gomakase generates Go projects with a DDD structure. It is the
+// library behind the gomakase command: each generator renders schematics
+// into an output filesystem, runs the hooks that follow it and returns the
+// events it reported.
+package gomakase
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+
+	"github.com/IrwantoCia/gomakase/embed"
+	"github.com/IrwantoCia/gomakase/internal/shared/archive"
+	"github.com/IrwantoCia/gomakase/internal/shared/command"
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+)
+
+type (
+	// Filesystem is the writable filesystem generated files go to, rooted
+	// at the project directory.
+	Filesystem = file.FS
+	// HookRunner runs the commands that follow a generation, like go mod
+	// tidy, in the project directory.
+	HookRunner = command.Runner
+	// MemFS is a Filesystem that keeps its files in memory.
+	MemFS     = file.MemFS
+	Event     = event.Event
+	EventType = event.Type
+	Emitter   = event.Emitter
+	Summary   = event.SummaryDetail
+)
+
+const (
+	FileCreated = event.FileCreated
+	FileSkipped = event.FileSkipped
+	ASTEdit     = event.ASTEdit
+	HookStarted = event.HookStarted
+	HookFailed  = event.HookFailed
+	Satisfied   = event.Satisfied
+	Error       = event.Error
+	SummaryType = event.Summary
+)
+
+// Schematics returns the schematics built into gomakase.
+func Schematics() fs.FS {
+	return embed.SchematicsFS
+}
+
+// DirFS returns a Filesystem for the directory dir on disk.
+func DirFS(dir string) Filesystem {
+	return file.DirFS(dir)
+}
+
+// NewMemFS returns an empty in-memory Filesystem, to preview a generation
+// without touching the disk.
+func NewMemFS() *MemFS {
+	return file.NewMemFS()
+}
+
+// WriteArchive writes the files of fsys to w as a .tar.gz, .tgz or .zip
+// archive, chosen by the extension of name. The files are placed under
+// dir, so extracting the archive creates that directory.
+func WriteArchive(w io.Writer, name string, fsys *MemFS, dir string) error {
+	format, err := archive.Format(name)
+	if err != nil {
+		return err
+	}
+	return archive.Write(w, format, fsys, dir)
+}
+
+// ExecHooks returns a HookRunner that executes hooks in dir.
+func ExecHooks(dir string) HookRunner {
+	return command.NewRunner(dir)
+}
+
+// Result is what a generator did. Summary.Success is false when any step or
+// hook failed.
+type Result struct {
+	Events  []Event
+	Summary Summary
+}
+
+// Files returns the paths of the files the generator created.
+func (r Result) Files() []string {
+	files := []string{}
+	for _, e := range r.Events {
+		if e.Type == FileCreated {
+			files = append(files, e.Path)
+		}
+	}
+	return files
+}
+
+// run records the events of a single generator and passes them on to the
+// Emitter given in its options.
+type run struct {
+	command  string
+	events   []Event
+	emitter  Emitter
+	recorder *event.Recorder
+}
+
+func newRun(command string, emitter Emitter) *run {
+	if emitter == nil {
+		emitter = event.Discard
+	}
+	r := &run{command: command, emitter: emitter}
+	r.recorder = event.NewRecorder(r)
+	return r
+}
+
+func (r *run) Emit(e Event) {
+	r.events = append(r.events, e)
+	r.emitter.Emit(e)
+}
+
+// fail reports err and finishes the run.
+func (r *run) fail(err error) (Result, error) {
+	r.recorder.Emit(Event{Type: Error, Error: err.Error()})
+	return r.finish(err)
+}
+
+// finish reports the summary and returns the result with err, the error
+// that ended the run if any.
+func (r *run) finish(err error) (Result, error) {
+	summary := r.recorder.Summary(r.command)
+	return Result{Events: r.events, Summary: summary}, err
+}
+
+func schematicsOrDefault(schematics fs.FS) fs.FS {
+	if schematics == nil {
+		return Schematics()
+	}
+	return schematics
+}
+
+// runHooks runs hooks with runner, then records the checksums of the
+// generated files they changed, such as go.mod after go mod tidy, so diff
+// does not report them as modified by the user.
+func runHooks(r *run, output Filesystem, runner HookRunner, hooks ...command.Hook) error {
+	files := file.New(output)
+	p, err := project.Load(files)
+	if err != nil || runner == nil {
+		return command.RunHooks(runner, r.recorder, hooks...)
+	}
+	before := map[string][]byte{}
+	for _, generated := range p.Files {
+		if content, err := files.ReadFile(generated.Path); err == nil {
+			before[generated.Path] = content
+		}
+	}
+
+	err = command.RunHooks(runner, r.recorder, hooks...)
+	changed := false
+	for path, content := range before {
+		after, readErr := files.ReadFile(path)
+		if readErr == nil && !bytes.Equal(after, content) {
+			p.RecordWrite(path, after)
+			changed = true
+		}
+	}
+	if changed {
+		if saveErr := project.Save(files, p); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	return err
+}
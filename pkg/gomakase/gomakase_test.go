@@ -0,0 +1,382 @@
+package gomakase
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+type recordingRunner struct {
+	hooks []string
+}
+
+func (r *recordingRunner) Run(name string, args ...string) error {
+	r.hooks = append(r.hooks, name)
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	output := NewMemFS()
+	hooks := &recordingRunner{}
+
+	result, err := NewProject(ProjectOptions{
+		Module:   "github.com/acme/demo",
+		Database: "postgres",
+		Output:   output,
+		Hooks:    hooks,
+	})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	assert.Equal(t, result.Summary.Success, true)
+	assert.Equal(t, result.Summary.FilesCreated, len(result.Files()))
+	assert.Equal(t, hooks.hooks, []string{"go", "npm"})
+	assert.Equal(t, len(output.Paths()), result.Summary.FilesCreated)
+	if _, err := output.Stat("cmd/server/router.go"); err != nil {
+		t.Fatalf("Expected router.go to be generated: %v", err)
+	}
+
+	result, err = AddContext(ContextOptions{Name: "order", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating context: %v", err)
+	}
+	assert.Equal(t, result.Summary.Success, true)
+	assert.Equal(t, result.Summary.HooksRun, 0)
+
+	result, err = AddPlugin(PluginOptions{Name: "auth", Output: output})
+	if err != nil {
+		t.Fatalf("Error adding plugin: %v", err)
+	}
+	assert.Equal(t, result.Summary.Success, true)
+	assert.NotEqual(t, result.Summary.ASTEdits, 0)
+
+	p, err := project.Load(file.New(output))
+	if err != nil {
+		t.Fatalf("Error loading project: %v", err)
+	}
+	assert.Equal(t, p.Name, "demo")
+	assert.Equal(t, p.Options.Database, "postgres")
+	assert.Equal(t, p.HasContext("order"), true)
+	assert.Equal(t, p.HasPlugin("auth"), true)
+}
+
+func TestAddContext_Nested(t *testing.T) {
+	output := NewMemFS()
+	_, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+
+	result, err := AddContext(ContextOptions{Name: "billing/OrderItem", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating context: %v", err)
+	}
+	assert.Equal(t, result.Summary.Success, true)
+
+	content, err := output.ReadFile("internal/billing/orderitem/application/orderitem.service.go")
+	if err != nil {
+		t.Fatalf("Expected the service to be generated: %v", err)
+	}
+	if !strings.Contains(string(content), "func NewOrderItemService(") ||
+		!strings.Contains(string(content), `"github.com/acme/demo/internal/billing/orderitem/domain"`) {
+		t.Fatalf("Unexpected service:\n%s", content)
+	}
+
+	// the context is wired into the router and its schema is migrated
+	content, err = output.ReadFile("cmd/server/router.go")
+	if err != nil {
+		t.Fatalf("Expected the router to be generated: %v", err)
+	}
+	for _, code := range []string{
+		`billingOrderItemInfra "github.com/acme/demo/internal/billing/orderitem/infrastructure"`,
+		"billingOrderItemService := billingOrderItemApp.NewOrderItemService(&billingOrderItemRepository, logger, config.Config)",
+		`router.GET("/billing/order-item/:id", billingOrderItemHandler.Get)`,
+	} {
+		if !strings.Contains(string(content), code) {
+			t.Fatalf("Expected %s in the router:\n%s", code, content)
+		}
+	}
+	content, err = output.ReadFile("cmd/server/migrations.go")
+	if err != nil {
+		t.Fatalf("Expected the schema list to be generated: %v", err)
+	}
+	if !strings.Contains(string(content), `billingOrderItemInfra "github.com/acme/demo/internal/billing/orderitem/infrastructure"`) ||
+		!strings.Contains(string(content), "&billingOrderItemInfra.OrderItemSchema{},") {
+		t.Fatalf("Unexpected schema list:\n%s", content)
+	}
+
+	p, err := project.Load(file.New(output))
+	if err != nil {
+		t.Fatalf("Error loading project: %v", err)
+	}
+	assert.Equal(t, p.Contexts, []string{"billing/order_item"})
+
+	// the same context under another spelling is already there
+	result, _ = AddContext(ContextOptions{Name: "billing/order_item", Output: output})
+	assert.Equal(t, result.Summary.FilesCreated, 0)
+	assert.Equal(t, result.Summary.FilesSkipped, 1)
+}
+
+func TestAddContext_SameName(t *testing.T) {
+	output := NewMemFS()
+	_, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+
+	// the router wires both invoice contexts under the names of their paths
+	for _, name := range []string{"billing/invoice", "shipping/invoice"} {
+		result, err := AddContext(ContextOptions{Name: name, Output: output})
+		if err != nil {
+			t.Fatalf("Error generating context %s: %v", name, err)
+		}
+		assert.Equal(t, result.Summary.Success, true)
+	}
+
+	content, err := output.ReadFile("cmd/server/router.go")
+	if err != nil {
+		t.Fatalf("Expected the router to be generated: %v", err)
+	}
+	for _, code := range []string{
+		`billingInvoiceApp "github.com/acme/demo/internal/billing/invoice/application"`,
+		`shippingInvoiceApp "github.com/acme/demo/internal/shipping/invoice/application"`,
+		"billingInvoiceHandler := billingInvoiceDelivery.NewInvoiceHandler(logger, &billingInvoiceService)",
+		"shippingInvoiceHandler := shippingInvoiceDelivery.NewInvoiceHandler(logger, &shippingInvoiceService)",
+		`router.GET("/shipping/invoice/:id", shippingInvoiceHandler.Get)`,
+	} {
+		if !strings.Contains(string(content), code) {
+			t.Fatalf("Expected %s in the router:\n%s", code, content)
+		}
+	}
+	content, err = output.ReadFile("cmd/server/migrations.go")
+	if err != nil {
+		t.Fatalf("Expected the schema list to be generated: %v", err)
+	}
+	if !strings.Contains(string(content), "&billingInvoiceInfra.InvoiceSchema{},") ||
+		!strings.Contains(string(content), "&shippingInvoiceInfra.InvoiceSchema{},") {
+		t.Fatalf("Unexpected schema list:\n%s", content)
+	}
+}
+
+func TestAddContext_FailedWiring(t *testing.T) {
+	output := NewMemFS()
+	_, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	descriptor, _ := output.ReadFile("gen.yaml")
+
+	// the wiring of the context declares orderRepository again
+	router, _ := output.ReadFile("cmd/server/router.go")
+	router = []byte(strings.Replace(string(router),
+		"logger logger.Logger) {\n",
+		"logger logger.Logger) {\n\torderRepository := 0\n\t_ = orderRepository\n", 1))
+	if err := output.WriteFile("cmd/server/router.go", router); err != nil {
+		t.Fatalf("Error writing router.go: %v", err)
+	}
+
+	result, err := AddContext(ContextOptions{Name: "order", Output: output})
+	if err == nil {
+		t.Fatal("Expected the redeclared variable to fail the context")
+	}
+	assert.Equal(t, result.Summary.Success, false)
+
+	content, _ := output.ReadFile("cmd/server/router.go")
+	assert.Equal(t, string(content), string(router))
+	content, _ = output.ReadFile("gen.yaml")
+	assert.Equal(t, string(content), string(descriptor))
+}
+
+func TestAddPlugin_Target(t *testing.T) {
+	output := NewMemFS()
+	_, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+
+	schematics := overrideFS{
+		base: Schematics(),
+		files: fstest.MapFS{
+			"schematics/plugins/trace/schematic.yaml": {Data: []byte(`description: "Adds tracing."
+version: "1.0.0"
+actions:
+  - type: add_statement
+    output: "cmd/server/main.go"
+    statement: "defer appLogger.Info(\"stopped\")"
+    function: main
+    anchor: after
+    match: "defer appLogger.Sync()"
+`)},
+		},
+	}
+	result, err := AddPlugin(PluginOptions{Name: "trace", Schematics: schematics, Output: output})
+	if err != nil {
+		t.Fatalf("Error adding plugin: %v", err)
+	}
+	assert.Equal(t, result.Summary.ASTEdits, 1)
+
+	content, err := output.ReadFile("cmd/server/main.go")
+	if err != nil {
+		t.Fatalf("Error reading main.go: %v", err)
+	}
+	expected := "\tdefer appLogger.Sync()\n\tdefer appLogger.Info(\"stopped\")\n\n\tif config.Config.LogLevel"
+	if !strings.Contains(string(content), expected) {
+		t.Fatalf("Expected the statement after defer appLogger.Sync():\n%s", content)
+	}
+}
+
+func TestAddPlugin_FailedEdit(t *testing.T) {
+	output := NewMemFS()
+	_, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	router, _ := output.ReadFile("cmd/server/router.go")
+
+	// the second dependency declares the first one again
+	schematics := overrideFS{
+		base: Schematics(),
+		files: fstest.MapFS{
+			"schematics/plugins/cache/schematic.yaml": {Data: []byte(`description: "Adds a cache."
+version: "1.0.0"
+actions:
+  - type: add_import
+    output: "cmd/server/router.go"
+    import: "github.com/acme/demo/internal/shared/cache"
+  - type: add_dependency
+    output: "cmd/server/router.go"
+    dependency: "store := cache.New()"
+  - type: add_dependency
+    output: "cmd/server/router.go"
+    dependency: "store := cache.NewRedis()"
+`)},
+		},
+	}
+	result, err := AddPlugin(PluginOptions{Name: "cache", Schematics: schematics, Output: output})
+	if err == nil {
+		t.Fatal("Expected the redeclared variable to fail the plugin")
+	}
+	assert.Equal(t, result.Summary.ASTEdits, 0)
+
+	content, _ := output.ReadFile("cmd/server/router.go")
+	assert.Equal(t, string(content), string(router))
+}
+
+func TestAddPlugin_Config(t *testing.T) {
+	output := NewMemFS()
+	_, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	content, _ := output.ReadFile("internal/shared/config/config.go")
+	if strings.Contains(string(content), "JWT") {
+		t.Fatalf("Expected no JWT settings before the auth plugin:\n%s", content)
+	}
+
+	if _, err := AddPlugin(PluginOptions{Name: "auth", Output: output}); err != nil {
+		t.Fatalf("Error adding plugin: %v", err)
+	}
+	content, _ = output.ReadFile("internal/shared/config/config.go")
+	for _, expected := range []string{
+		"JWT       JWTConfig       `mapstructure:\"JWT\"`",
+		"AccessTokenExp time.Duration `mapstructure:\"JWT_ACCESS_TOKEN_EXP\"`",
+		`viper.SetDefault("JWT.JWT_ACCESS_TOKEN_EXP", "24h")`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Fatalf("Expected %s in config.go:\n%s", expected, content)
+		}
+	}
+	content, _ = output.ReadFile(".env.example")
+	if !strings.Contains(string(content), "\n# Lifetime of the access tokens\nJWT.JWT_ACCESS_TOKEN_EXP=24h\n") {
+		t.Fatalf("Expected the JWT settings in .env.example:\n%s", content)
+	}
+}
+
+func TestAddPlugin_ImportAlias(t *testing.T) {
+	output := NewMemFS()
+	_, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output})
+	if err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	// the user already imports another package as authApp
+	content, _ := output.ReadFile("cmd/server/router.go")
+	content = []byte(strings.Replace(string(content), "import (\n", "import (\n\tauthApp \"github.com/acme/demo/internal/legacy/auth\"\n", 1))
+	output.WriteFile("cmd/server/router.go", content)
+
+	result, err := AddPlugin(PluginOptions{Name: "auth", Output: output})
+	if err != nil {
+		t.Fatalf("Error adding plugin: %v", err)
+	}
+	assert.Equal(t, result.Summary.Success, true)
+
+	content, _ = output.ReadFile("cmd/server/router.go")
+	for _, expected := range []string{
+		`authApp2 "github.com/acme/demo/internal/auth/application"`,
+		`authService := authApp2.NewAuthService(&authRepository, config.Config, logger)`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Fatalf("Expected %s in router.go:\n%s", expected, content)
+		}
+	}
+}
+
+func TestAddPlugin_NotFound(t *testing.T) {
+	result, err := AddPlugin(PluginOptions{Name: "missing", Output: NewMemFS()})
+	if err == nil {
+		t.Fatalf("Expected an error for a missing plugin")
+	}
+	assert.Equal(t, result.Summary.Success, false)
+	assert.Equal(t, result.Events[0].Type, Error)
+}
+
+func TestApply(t *testing.T) {
+	spec, err := ParseSpec([]byte(`module: github.com/acme/shop
+contexts:
+  - order
+plugins:
+  - name: auth
+`))
+	if err != nil {
+		t.Fatalf("Error parsing spec: %v", err)
+	}
+	output := NewMemFS()
+	hooks := &recordingRunner{}
+
+	result, err := Apply(ApplyOptions{Spec: spec, Output: output, Hooks: hooks})
+	if err != nil {
+		t.Fatalf("Error applying spec: %v", err)
+	}
+	assert.Equal(t, result.Summary.Success, true)
+	// the order context imported the config package the plugin imports
+	assert.Equal(t, result.Summary.Satisfied, 1)
+	for _, e := range result.Events {
+		if e.Type == Satisfied {
+			assert.Equal(t, e.Action, "add_import")
+			assert.Equal(t, e.Detail, "github.com/acme/shop/internal/shared/config")
+		}
+	}
+	assert.Equal(t, hooks.hooks, []string{"go", "npm"})
+	files := len(output.Paths())
+
+	// applying the same spec again only reports what is satisfied
+	hooks.hooks = nil
+	result, err = Apply(ApplyOptions{Spec: spec, Output: output, Hooks: hooks})
+	if err != nil {
+		t.Fatalf("Error applying spec again: %v", err)
+	}
+	assert.Equal(t, result.Summary.Satisfied, 3)
+	assert.Equal(t, result.Summary.FilesCreated, 0)
+	assert.Equal(t, len(hooks.hooks), 0)
+	assert.Equal(t, len(output.Paths()), files)
+
+	spec.Options.Database = "postgres"
+	result, err = Apply(ApplyOptions{Spec: spec, Output: output})
+	if err == nil {
+		t.Fatalf("Expected an error when the options changed")
+	}
+	assert.Equal(t, result.Summary.Success, false)
+}
@@ -0,0 +1,63 @@
+package gomakase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IrwantoCia/gomakase/internal/graph_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+)
+
+type (
+	// DependencyGraph is the layers of the contexts of a project and their
+	// imports. Its Mermaid and DOT methods render it.
+	DependencyGraph = application.Graph
+	// Layer is a layer of a context, e.g. the domain of order.
+	Layer = application.Layer
+	// Import is an import of a file of a layer.
+	Import = application.Import
+	// Violation is an import that crosses a boundary between layers.
+	Violation = application.Violation
+)
+
+type GraphOptions struct {
+	// Output is rooted at the project directory, next to gen.yaml. Graph
+	// and Check only read from it, and it must list directories, as DirFS
+	// and MemFS do.
+	Output Filesystem
+}
+
+// Graph reads the imports of the domain, application, delivery and
+// infrastructure layers of every context under internal.
+func Graph(opts GraphOptions) (*DependencyGraph, error) {
+	graphService, err := newGraphService(opts)
+	if err != nil {
+		return nil, err
+	}
+	return graphService.Graph()
+}
+
+// Check returns the imports that cross the boundaries of the layers: a
+// domain importing gorm, gin or an infrastructure layer, an application
+// importing a delivery layer, or a context importing the infrastructure of
+// another context.
+func Check(opts GraphOptions) ([]Violation, error) {
+	graphService, err := newGraphService(opts)
+	if err != nil {
+		return nil, err
+	}
+	return graphService.Check()
+}
+
+func newGraphService(opts GraphOptions) (application.GraphService, error) {
+	if opts.Output == nil {
+		return nil, errors.New("output filesystem is required")
+	}
+	file := file.New(opts.Output)
+	p, err := project.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("loading project descriptor: %w", err)
+	}
+	return application.NewGraphService(file, p), nil
+}
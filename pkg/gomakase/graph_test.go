@@ -0,0 +1,82 @@
+package gomakase
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestCheck(t *testing.T) {
+	output := NewMemFS()
+	if _, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output}); err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	for _, name := range []string{"order", "billing/invoice"} {
+		if _, err := AddContext(ContextOptions{Name: name, Output: output}); err != nil {
+			t.Fatalf("Error generating context %s: %v", name, err)
+		}
+	}
+
+	// the generated layers keep to their boundaries
+	violations, err := Check(GraphOptions{Output: output})
+	if err != nil {
+		t.Fatalf("Error checking project: %v", err)
+	}
+	assert.Equal(t, len(violations), 0)
+
+	graph, err := Graph(GraphOptions{Output: output})
+	if err != nil {
+		t.Fatalf("Error reading graph: %v", err)
+	}
+	assert.Equal(t, graph.Layers[0], Layer{Context: "billing/invoice", Name: "domain"})
+	if !strings.Contains(graph.Mermaid(), "  order_delivery --> order_application\n") {
+		t.Fatalf("Expected the delivery to depend on the application:\n%s", graph.Mermaid())
+	}
+
+	err = output.WriteFile("internal/order/domain/order.gorm.go", []byte(`package domain
+
+import (
+	"gorm.io/gorm"
+
+	invoiceInfra "github.com/acme/demo/internal/billing/invoice/infrastructure"
+)
+
+var _ = gorm.Open
+var _ = invoiceInfra.InvoiceSchema{}
+`))
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	err = output.WriteFile("internal/order/application/order.http.go", []byte(`package application
+
+import _ "github.com/acme/demo/internal/order/delivery"
+`))
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	violations, err = Check(GraphOptions{Output: output})
+	if err != nil {
+		t.Fatalf("Error checking project: %v", err)
+	}
+	rules := []string{}
+	for _, violation := range violations {
+		rules = append(rules, violation.Rule+" "+violation.Import.Path)
+	}
+	assert.Equal(t, rules, []string{
+		"domain-is-pure gorm.io/gorm",
+		"domain-is-pure github.com/acme/demo/internal/billing/invoice/infrastructure",
+		"infrastructure-is-private github.com/acme/demo/internal/billing/invoice/infrastructure",
+		"application-not-delivery github.com/acme/demo/internal/order/delivery",
+	})
+	assert.Equal(t, violations[0].String(), "internal/order/domain/order.gorm.go:4: order/domain imports gorm.io/gorm, but domain must not import gorm, gin or an infrastructure layer (domain-is-pure)")
+
+	graph, err = Graph(GraphOptions{Output: output})
+	if err != nil {
+		t.Fatalf("Error reading graph: %v", err)
+	}
+	if !strings.Contains(graph.DOT(), "  order_domain -> billing_invoice_infrastructure [color=red];\n") {
+		t.Fatalf("Expected the violation to be red:\n%s", graph.DOT())
+	}
+}
@@ -0,0 +1,93 @@
+package gomakase
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/IrwantoCia/gomakase/internal/add_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/command"
+	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
+)
+
+type PluginOptions struct {
+	// Name is the name of the plugin, e.g. auth.
+	Name string
+	// Variables override the values of the plugin's variables.
+	Variables map[string]string
+
+	// Schematics defaults to the built-in schematics.
+	Schematics fs.FS
+	// Output is rooted at the project directory, next to gen.yaml.
+	Output Filesystem
+	// Hooks runs go mod tidy and npm install. A nil Hooks runs nothing.
+	Hooks HookRunner
+	// Events receives every event as it happens.
+	Events Emitter
+}
+
+// Plugins returns the names of the plugins in schematics, or in the
+// built-in schematics when it is nil.
+func Plugins(schematics fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(schematicsOrDefault(schematics), path.Join(schematic.Root, "plugins"))
+	if err != nil {
+		return nil, err
+	}
+	plugins := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			plugins = append(plugins, entry.Name())
+		}
+	}
+	return plugins, nil
+}
+
+// AddPlugin adds a plugin to the project in opts.Output.
+func AddPlugin(opts PluginOptions) (Result, error) {
+	r := newRun("add", opts.Events)
+	err := addPlugin(r, opts)
+	if err != nil {
+		return r.fail(err)
+	}
+	err = runHooks(r, opts.Output, opts.Hooks, command.GoModTidy, command.NPMInstall)
+	return r.finish(err)
+}
+
+func addPlugin(r *run, opts PluginOptions) error {
+	if opts.Name == "" {
+		return errors.New("plugin name is required")
+	}
+	if opts.Output == nil {
+		return errors.New("output filesystem is required")
+	}
+	schematics := schematicsOrDefault(opts.Schematics)
+	file := file.New(opts.Output)
+
+	content, err := fs.ReadFile(schematics, path.Join(schematic.Root, "plugins", opts.Name, "schematic.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("plugin %s not found, see gomakase list", opts.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("reading plugin schematic: %w", err)
+	}
+	pluginSchematic, err := config.LoadSchematic[config.PluginSchematic](content)
+	if err != nil {
+		return fmt.Errorf("loading plugin schematic: %w", err)
+	}
+
+	p, err := project.Load(file)
+	if err != nil {
+		return fmt.Errorf("loading project descriptor: %w", err)
+	}
+
+	addService := application.NewAddService(p, pluginSchematic, opts.Variables, schematics, file, r.recorder)
+	err = addService.Generate(opts.Name)
+	if err != nil {
+		return fmt.Errorf("adding plugin: %w", err)
+	}
+	return nil
+}
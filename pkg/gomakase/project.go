@@ -0,0 +1,105 @@
+package gomakase
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/IrwantoCia/gomakase/internal/new_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/command"
+	"github.com/IrwantoCia/gomakase/internal/shared/config"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+	"github.com/IrwantoCia/gomakase/internal/shared/schematic"
+)
+
+type ProjectOptions struct {
+	// Module is the Go module path of the project.
+	Module string
+	// Name defaults to the last element of Module.
+	Name string
+	// Database, Frontend and HTTPFramework default to the first supported
+	// value of each option.
+	Database      string
+	Frontend      string
+	HTTPFramework string
+	// Variables are custom template variables recorded in gen.yaml.
+	Variables map[string]string
+
+	// Schematics defaults to the built-in schematics.
+	Schematics fs.FS
+	// Output is rooted at the directory of the new project.
+	Output Filesystem
+	// Hooks runs go mod tidy and npm install. A nil Hooks runs nothing.
+	Hooks HookRunner
+	// Events receives every event as it happens.
+	Events Emitter
+}
+
+// NewProject generates a new project into opts.Output. The directory may
+// already exist as long as none of the generated files are in it.
+func NewProject(opts ProjectOptions) (Result, error) {
+	r := newRun("new", opts.Events)
+	err := newProject(r, opts)
+	if err != nil {
+		return r.fail(err)
+	}
+	err = runHooks(r, opts.Output, opts.Hooks, command.GoModTidy, command.NPMInstall)
+	return r.finish(err)
+}
+
+func newProject(r *run, opts ProjectOptions) error {
+	if opts.Module == "" {
+		return errors.New("module is required")
+	}
+	if opts.Output == nil {
+		return errors.New("output filesystem is required")
+	}
+	schematics := schematicsOrDefault(opts.Schematics)
+
+	content, err := fs.ReadFile(schematics, path.Join(schematic.Root, "project", "schematic.yaml"))
+	if err != nil {
+		return fmt.Errorf("reading project schematic: %w", err)
+	}
+	projectSchematic, err := config.LoadSchematic[config.ProjectSchematic](content)
+	if err != nil {
+		return fmt.Errorf("loading project schematic: %w", err)
+	}
+	if len(projectSchematic.Actions) == 0 {
+		return errors.New("no actions found in project schematic")
+	}
+
+	options := project.DefaultOptions()
+	if opts.Database != "" {
+		options.Database = opts.Database
+	}
+	if opts.Frontend != "" {
+		options.Frontend = opts.Frontend
+	}
+	if opts.HTTPFramework != "" {
+		options.HTTPFramework = opts.HTTPFramework
+	}
+	err = options.Validate()
+	if err != nil {
+		return err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = path.Base(opts.Module)
+	}
+	p := &project.Project{
+		Name:      name,
+		Module:    opts.Module,
+		Options:   options,
+		Variables: opts.Variables,
+	}
+
+	newService := application.NewNewService(file.New(opts.Output), schematics, r.recorder)
+	err = newService.Generate(p, projectSchematic)
+	if err != nil {
+		return fmt.Errorf("generating project: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package gomakase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IrwantoCia/gomakase/internal/route_context/application"
+	"github.com/IrwantoCia/gomakase/internal/shared/event"
+	"github.com/IrwantoCia/gomakase/internal/shared/file"
+	"github.com/IrwantoCia/gomakase/internal/shared/parser"
+	"github.com/IrwantoCia/gomakase/internal/shared/project"
+)
+
+// Route is an HTTP route of a project.
+type Route = parser.Route
+
+type ProtectOptions struct {
+	// Prefix is the path prefix of the routes to protect, e.g. /admin.
+	Prefix string
+
+	// Output is rooted at the project directory, next to gen.yaml.
+	Output Filesystem
+	// Events receives every event as it happens.
+	Events Emitter
+}
+
+// ProtectRoutes moves the routes under opts.Prefix in cmd/server/router.go
+// into a route group that requires the auth plugin's middleware.
+func ProtectRoutes(opts ProtectOptions) (Result, error) {
+	r := newRun("route protect", opts.Events)
+	if opts.Output == nil {
+		return r.fail(errors.New("output filesystem is required"))
+	}
+	file := file.New(opts.Output)
+
+	p, err := project.Load(file)
+	if err != nil {
+		return r.fail(fmt.Errorf("loading project descriptor: %w", err))
+	}
+
+	routeService := application.NewRouteService(file, p, r.recorder)
+	err = routeService.Protect(opts.Prefix)
+	if err != nil {
+		return r.fail(fmt.Errorf("protecting routes: %w", err))
+	}
+	return r.finish(nil)
+}
+
+type RoutesOptions struct {
+	// Output is rooted at the project directory, next to gen.yaml. Routes
+	// only reads from it, and follows the router into the other files of a
+	// package when it can list directories, as DirFS and MemFS can.
+	Output Filesystem
+}
+
+// Routes returns the routes registered in cmd/server/router.go, and in the
+// functions it passes the router or a group to, in the order they are
+// registered.
+func Routes(opts RoutesOptions) ([]Route, error) {
+	if opts.Output == nil {
+		return nil, errors.New("output filesystem is required")
+	}
+	file := file.New(opts.Output)
+	p, err := project.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("loading project descriptor: %w", err)
+	}
+	routeService := application.NewRouteService(file, p, event.Discard)
+	return routeService.List()
+}
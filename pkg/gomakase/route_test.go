@@ -0,0 +1,89 @@
+package gomakase
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestProtectRoutes(t *testing.T) {
+	output := NewMemFS()
+	if _, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output}); err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+
+	// protecting needs the middleware of the auth plugin
+	if _, err := ProtectRoutes(ProtectOptions{Prefix: "/health", Output: output}); err == nil {
+		t.Fatalf("Expected an error without the auth plugin")
+	}
+
+	if _, err := AddPlugin(PluginOptions{Name: "auth", Output: output}); err != nil {
+		t.Fatalf("Error adding plugin: %v", err)
+	}
+	// the trailing slash is not part of the prefix of the group
+	result, err := ProtectRoutes(ProtectOptions{Prefix: "/health/", Output: output})
+	if err != nil {
+		t.Fatalf("Error protecting routes: %v", err)
+	}
+	assert.Equal(t, result.Summary.ASTEdits, 1)
+
+	content, err := output.ReadFile("cmd/server/router.go")
+	if err != nil {
+		t.Fatalf("Error reading router.go: %v", err)
+	}
+	expected := "\thealthGroup := router.Group(\"/health\", middleware.AuthMiddleware(&authService))\n\thealthGroup.GET(\"\", func(c *gin.Context) {"
+	if !strings.Contains(string(content), expected) || strings.Contains(string(content), `router.GET("/health"`) {
+		t.Fatalf("Expected /health to be moved into a protected group:\n%s", content)
+	}
+
+	result, err = ProtectRoutes(ProtectOptions{Prefix: "/health", Output: output})
+	if err != nil {
+		t.Fatalf("Error protecting routes again: %v", err)
+	}
+	assert.Equal(t, result.Summary.Satisfied, 1)
+
+	if _, err := ProtectRoutes(ProtectOptions{Prefix: "/missing", Output: output}); err == nil {
+		t.Fatalf("Expected an error for a prefix without routes")
+	}
+
+	// the routes are not moved into a group without the middleware
+	router := strings.Replace(string(content),
+		"logger logger.Logger) {\n",
+		"logger logger.Logger) {\n\tapi := router.Group(\"/api\")\n\tapi.GET(\"/status\", status)\n\trouter.GET(\"/api/items\", items)\n", 1)
+	if err := output.WriteFile("cmd/server/router.go", []byte(router)); err != nil {
+		t.Fatalf("Error writing router.go: %v", err)
+	}
+	_, err = ProtectRoutes(ProtectOptions{Prefix: "/api", Output: output})
+	if err == nil || !strings.Contains(err.Error(), "group api") {
+		t.Fatalf("Expected an error naming the unauthenticated group, got %v", err)
+	}
+	content, _ = output.ReadFile("cmd/server/router.go")
+	assert.Equal(t, string(content), router)
+}
+
+func TestRoutes(t *testing.T) {
+	output := NewMemFS()
+	if _, err := NewProject(ProjectOptions{Module: "github.com/acme/demo", Output: output}); err != nil {
+		t.Fatalf("Error generating project: %v", err)
+	}
+	if _, err := AddPlugin(PluginOptions{Name: "auth", Output: output}); err != nil {
+		t.Fatalf("Error adding plugin: %v", err)
+	}
+	if _, err := ProtectRoutes(ProtectOptions{Prefix: "/health", Output: output}); err != nil {
+		t.Fatalf("Error protecting routes: %v", err)
+	}
+
+	routes, err := Routes(RoutesOptions{Output: output})
+	if err != nil {
+		t.Fatalf("Error listing routes: %v", err)
+	}
+	byPath := map[string]Route{}
+	for _, route := range routes {
+		byPath[route.Method+" "+route.Path] = route
+	}
+	assert.Equal(t, byPath["POST /login"].Handler, "authHandler.Login")
+	health := byPath["GET /health"]
+	assert.Equal(t, health.Group, "healthGroup")
+	assert.Equal(t, health.Middlewares, []string{"middleware.AuthMiddleware(&authService)"})
+}